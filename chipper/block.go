@@ -2,6 +2,9 @@ package chipper
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +20,26 @@ type BlockInterface interface {
 	Chunks() []BlockInterface
 	Tables() []BlockInterface
 	Sections() []BlockInterface
+
+	// Accessors for the fields every node type shares through Block, so
+	// generic code needs no type switch on the concrete node type.
+	NodeTag() string
+	NodeLevel() int
+	NodePage() int
+	NodeBlockIdx() int
+	NodeBbox() []float64
+	NodeSentences() []string
+	ParentNode() BlockInterface
+	ChildNodes() []BlockInterface
+
+	// Navigation among siblings and in document order, kept up to date by
+	// AddChild so it needs no walk from the root.
+	Index() int
+	Depth() int
+	NextSibling() BlockInterface
+	PrevSibling() BlockInterface
+	Next() BlockInterface
+	Prev() BlockInterface
 }
 
 type Block struct {
@@ -31,6 +54,13 @@ type Block struct {
 	Children  []BlockInterface
 	Parent    BlockInterface
 	BlockJSON map[string]interface{}
+	// node is the Paragraph, Section, ListItem or Table embedding the block,
+	// which AddChild records as the Parent of its children.
+	node BlockInterface
+	// index is the position of the block among the children of its parent
+	// and depth its distance from the root, both set by AddChild.
+	index int
+	depth int
 }
 
 func NewBlock(blockJSON map[string]interface{}) *Block {
@@ -86,18 +116,153 @@ func NewBlock(blockJSON map[string]interface{}) *Block {
 
 func (b *Block) AddChild(node BlockInterface) {
 	b.Children = append(b.Children, node)
-	switch childNode := node.(type) {
-	case *Block:
-		childNode.Parent = b
-	case *Paragraph:
-		childNode.Parent = b
-	case *Section:
-		childNode.Parent = b
-	case *ListItem:
-		childNode.Parent = b
-	case *Table:
-		childNode.Parent = b
+	if child := blockOf(node); child != nil {
+		child.Parent = b.self()
+		child.index = len(b.Children) - 1
+		child.setDepth(b.depth + 1)
+	}
+}
+
+// setDepth sets the depth of b and its descendants, which only has work to do
+// below b when a subtree is moved.
+func (b *Block) setDepth(depth int) {
+	b.depth = depth
+	for _, node := range b.Children {
+		if child := blockOf(node); child != nil && child.depth != depth+1 {
+			child.setDepth(depth + 1)
+		}
+	}
+	if table, ok := b.node.(*Table); ok {
+		table.adoptCellNodes()
+	}
+}
+
+// reindexChildren renumbers the children of b after they were rearranged
+// without AddChild.
+func (b *Block) reindexChildren() {
+	for i, node := range b.Children {
+		if child := blockOf(node); child != nil {
+			child.index = i
+		}
+	}
+}
+
+// self returns the node embedding b, or b itself for a plain Block.
+func (b *Block) self() BlockInterface {
+	if b.node != nil {
+		return b.node
+	}
+	return b
+}
+
+func (b *Block) base() *Block {
+	return b
+}
+
+func (b *Block) NodeTag() string {
+	return b.Tag
+}
+
+func (b *Block) NodeLevel() int {
+	return b.Level
+}
+
+func (b *Block) NodePage() int {
+	return b.PageIdx
+}
+
+func (b *Block) NodeBlockIdx() int {
+	return b.BlockIdx
+}
+
+func (b *Block) NodeBbox() []float64 {
+	return b.Bbox
+}
+
+func (b *Block) NodeSentences() []string {
+	return b.Sentences
+}
+
+// ParentNode returns the node holding b as a child, or nil for the root.
+func (b *Block) ParentNode() BlockInterface {
+	return b.Parent
+}
+
+func (b *Block) ChildNodes() []BlockInterface {
+	return b.Children
+}
+
+// Index returns the position of b among the children of its parent, 0 for the
+// root.
+func (b *Block) Index() int {
+	return b.index
+}
+
+// Depth returns the number of ancestors of b, so the root is at depth 0 and
+// the top-level blocks of a document at depth 1.
+func (b *Block) Depth() int {
+	return b.depth
+}
+
+// siblings returns the children of the parent of b, or nil when b is not
+// among them: the root, and the blocks nested in table cells, which hang off
+// the table without being its children.
+func (b *Block) siblings() []BlockInterface {
+	if b.Parent == nil {
+		return nil
+	}
+	if siblings := b.Parent.ChildNodes(); b.index < len(siblings) && siblings[b.index] == b.self() {
+		return siblings
 	}
+	return nil
+}
+
+// NextSibling returns the child of the parent of b following it, or nil.
+func (b *Block) NextSibling() BlockInterface {
+	if siblings := b.siblings(); b.index+1 < len(siblings) {
+		return siblings[b.index+1]
+	}
+	return nil
+}
+
+// PrevSibling returns the child of the parent of b preceding it, or nil.
+func (b *Block) PrevSibling() BlockInterface {
+	if siblings := b.siblings(); b.index > 0 && siblings != nil {
+		return siblings[b.index-1]
+	}
+	return nil
+}
+
+// Next returns the node following b in document order, as Descendants yields
+// them: its first child, else the next sibling of b or of its closest
+// ancestor having one. It returns nil at the end of the document.
+func (b *Block) Next() BlockInterface {
+	if len(b.Children) > 0 {
+		return b.Children[0]
+	}
+	for node := b.self(); node != nil; node = node.ParentNode() {
+		if next := node.NextSibling(); next != nil {
+			return next
+		}
+	}
+	return nil
+}
+
+// Prev returns the node preceding b in document order: the last descendant of
+// its previous sibling, else its parent. It returns nil for the first block
+// of the document, since the untagged root is not a block of it.
+func (b *Block) Prev() BlockInterface {
+	prev := b.PrevSibling()
+	if prev == nil {
+		if b.Parent == nil || b.Parent.ParentNode() == nil {
+			return nil
+		}
+		return b.Parent
+	}
+	for children := prev.ChildNodes(); len(children) > 0; children = prev.ChildNodes() {
+		prev = children[len(children)-1]
+	}
+	return prev
 }
 
 func (b *Block) ToHTML(includeChildren, recurse bool) string {
@@ -112,23 +277,8 @@ func (b *Block) ToText(includeChildren, recurse bool) string {
 
 func (b *Block) ParentChain() []BlockInterface {
 	var chain []BlockInterface
-	parent := b.Parent
-	for parent != nil {
+	for parent := b.Parent; parent != nil; parent = parent.ParentNode() {
 		chain = append([]BlockInterface{parent}, chain...)
-		switch parentBlock := parent.(type) {
-		case *Block:
-			parent = parentBlock.Parent
-		case *Paragraph:
-			parent = parentBlock.Parent
-		case *Section:
-			parent = parentBlock.Parent
-		case *ListItem:
-			parent = parentBlock.Parent
-		case *Table:
-			parent = parentBlock.Parent
-		default:
-			parent = nil
-		}
 	}
 	return chain
 }
@@ -137,9 +287,9 @@ func (b *Block) ParentText() string {
 	parentChain := b.ParentChain()
 	var headerTexts, paraTexts []string
 	for _, p := range parentChain {
-		if p.(*Block).Tag == "header" {
+		if p.NodeTag() == "header" {
 			headerTexts = append(headerTexts, p.ToText(false, false))
-		} else if p.(*Block).Tag == "list_item" || p.(*Block).Tag == "para" {
+		} else if p.NodeTag() == "list_item" || p.NodeTag() == "para" {
 			paraTexts = append(paraTexts, p.ToText(false, false))
 		}
 	}
@@ -166,82 +316,32 @@ func (b *Block) ToContextText(includeSectionInfo bool) string {
 	return text
 }
 
+// IterChildren calls nodeVisitor for every descendant of node in document
+// order. level is ignored: it only ever counted the recursion depth and never
+// affected which nodes are visited.
+//
+// Deprecated: Use Walk, which also reports the depth and path of every node
+// and can skip subtrees, or Descendants.
 func (b *Block) IterChildren(node BlockInterface, level int, nodeVisitor func(BlockInterface)) {
-	switch nodeBlock := node.(type) {
-	case *Block:
-		for _, child := range nodeBlock.Children {
-			nodeVisitor(child)
-			b.IterChildren(child, level+1, nodeVisitor)
-		}
-	case *Paragraph:
-		for _, child := range nodeBlock.Children {
-			nodeVisitor(child)
-			b.IterChildren(child, level+1, nodeVisitor)
-		}
-	case *Section:
-		for _, child := range nodeBlock.Children {
-			nodeVisitor(child)
-			b.IterChildren(child, level+1, nodeVisitor)
-		}
-	case *ListItem:
-		for _, child := range nodeBlock.Children {
-			nodeVisitor(child)
-			b.IterChildren(child, level+1, nodeVisitor)
-		}
-	case *Table:
-		for _, child := range nodeBlock.Children {
-			nodeVisitor(child)
-			b.IterChildren(child, level+1, nodeVisitor)
-		}
+	for child := range Descendants(node) {
+		nodeVisitor(child)
 	}
 }
 
 func (b *Block) Paragraphs() []BlockInterface {
-	var paragraphs []BlockInterface
-	paraCollector := func(node BlockInterface) {
-		switch nodeBlock := node.(type) {
-		case *Paragraph:
-			paragraphs = append(paragraphs, nodeBlock)
-		}
-	}
-	b.IterChildren(b, 0, paraCollector)
-	return paragraphs
+	return slices.Collect(filterSeq(Descendants(b), isParagraph))
 }
 
 func (b *Block) Chunks() []BlockInterface {
-	var chunks []BlockInterface
-	chunkCollector := func(node BlockInterface) {
-		switch nodeBlock := node.(type) {
-		case *Paragraph, *ListItem, *Table:
-			chunks = append(chunks, nodeBlock)
-		}
-	}
-	b.IterChildren(b, 0, chunkCollector)
-	return chunks
+	return slices.Collect(filterSeq(Descendants(b), isLeafChunk))
 }
 
 func (b *Block) Tables() []BlockInterface {
-	var tables []BlockInterface
-	tableCollector := func(node BlockInterface) {
-		switch nodeBlock := node.(type) {
-		case *Table:
-			tables = append(tables, nodeBlock)
-		}
-	}
-	b.IterChildren(b, 0, tableCollector)
-	return tables
+	return slices.Collect(filterSeq(Descendants(b), isTable))
 }
 
 func (b *Block) Sections() []BlockInterface {
-	var sections []BlockInterface
-	sectionCollector := func(node BlockInterface) {
-		switch nodeBlock := node.(type) {
-		case *Section:
-			sections = append(sections, nodeBlock)
-		}
-	}
-	b.IterChildren(b, 0, sectionCollector)
-	return sections
+	return slices.Collect(filterSeq(Descendants(b), isSection))
 }
 
 type Paragraph struct {
@@ -249,9 +349,11 @@ type Paragraph struct {
 }
 
 func NewParagraph(paraJSON map[string]interface{}) *Paragraph {
-	return &Paragraph{
+	paragraph := &Paragraph{
 		Block: NewBlock(paraJSON),
 	}
+	paragraph.node = paragraph
+	return paragraph
 }
 
 func (p *Paragraph) ToText(includeChildren, recurse bool) string {
@@ -288,6 +390,7 @@ func NewSection(sectionJSON map[string]interface{}) *Section {
 		Block: NewBlock(sectionJSON),
 	}
 	section.Title = strings.Join(section.Sentences, "\n")
+	section.node = section
 	return section
 }
 
@@ -318,9 +421,11 @@ type ListItem struct {
 }
 
 func NewListItem(listJSON map[string]interface{}) *ListItem {
-	return &ListItem{
+	listItem := &ListItem{
 		Block: NewBlock(listJSON),
 	}
+	listItem.node = listItem
+	return listItem
 }
 
 func (li *ListItem) ToText(includeChildren, recurse bool) string {
@@ -351,7 +456,14 @@ type TableCell struct {
 	*Block
 	ColSpan   int         `json:"col_span"`
 	CellValue interface{} `json:"cell_value"`
-	CellNode  *Paragraph
+	// CellNode is the first nested block when it is a paragraph, kept for
+	// callers predating Nodes.
+	CellNode *Paragraph
+	// Nodes holds the blocks nested in the cell when cell_value is a block
+	// or a list of blocks, arranged like a document with list items nested by
+	// level. Their parent is the table holding the cell, though they are not
+	// among its children.
+	Nodes []BlockInterface
 }
 
 func NewTableCell(cellJSON map[string]interface{}) *TableCell {
@@ -367,34 +479,111 @@ func NewTableCell(cellJSON map[string]interface{}) *TableCell {
 
 	cell.CellValue = cellJSON["cell_value"]
 
-	if _, ok := cell.CellValue.(string); !ok {
-		cell.CellNode = NewParagraph(cell.CellValue.(map[string]interface{}))
+	var blocksJSON []interface{}
+	switch value := cell.CellValue.(type) {
+	case map[string]interface{}:
+		blocksJSON = append(blocksJSON, cellBlockJSON(value))
+	case []interface{}:
+		for _, item := range value {
+			switch itemValue := item.(type) {
+			case map[string]interface{}:
+				blocksJSON = append(blocksJSON, cellBlockJSON(itemValue))
+			case string:
+				blocksJSON = append(blocksJSON, map[string]interface{}{"tag": "para", "sentences": []interface{}{itemValue}})
+			}
+		}
+	}
+	if len(blocksJSON) > 0 {
+		reader := &LayoutReader{}
+		cell.Nodes = childrenOf(reader.Read(blocksJSON))
+		if paragraph, ok := cell.Nodes[0].(*Paragraph); ok {
+			cell.CellNode = paragraph
+		}
+		cell.inheritPosition()
 	}
 
 	return cell
 }
 
+// cellBlockJSON defaults the tag of a block nested in a cell to "para".
+func cellBlockJSON(blockJSON map[string]interface{}) map[string]interface{} {
+	if _, ok := blockJSON["tag"].(string); ok {
+		return blockJSON
+	}
+	tagged := make(map[string]interface{}, len(blockJSON)+1)
+	for key, value := range blockJSON {
+		tagged[key] = value
+	}
+	tagged["tag"] = "para"
+	return tagged
+}
+
+// inheritPosition gives a cell without its own page or bbox those of the
+// blocks nested in it, covering nested list items as well.
+func (tc *TableCell) inheritPosition() {
+	tc.inheritFrom(tc.Nodes)
+}
+
+func (tc *TableCell) inheritFrom(nodes []BlockInterface) {
+	for _, node := range nodes {
+		block := blockOf(node)
+		if tc.PageIdx < 0 {
+			tc.PageIdx = block.PageIdx
+		}
+		if len(block.Bbox) == 4 && block.PageIdx == tc.PageIdx {
+			if len(tc.Bbox) != 4 {
+				tc.Bbox = append([]float64(nil), block.Bbox...)
+			} else {
+				tc.Bbox[0] = min(tc.Bbox[0], block.Bbox[0])
+				tc.Bbox[1] = min(tc.Bbox[1], block.Bbox[1])
+				tc.Bbox[2] = max(tc.Bbox[2], block.Bbox[2])
+				tc.Bbox[3] = max(tc.Bbox[3], block.Bbox[3])
+			}
+		}
+		tc.inheritFrom(childrenOf(node))
+	}
+}
+
 func (tc *TableCell) ToText() string {
 	cellText := ""
 	switch value := tc.CellValue.(type) {
 	case string:
 		cellText = value
+	case float64:
+		cellText = strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		cellText = strconv.FormatBool(value)
 	default:
-		if tc.CellNode != nil {
-			cellText = tc.CellNode.ToText(false, false)
+		var texts []string
+		for _, node := range tc.Nodes {
+			texts = append(texts, node.ToText(true, true))
 		}
+		cellText = strings.Join(texts, "\n")
 	}
 	return cellText
 }
 
 func (tc *TableCell) ToHTML() string {
 	cellHTML := ""
-	switch value := tc.CellValue.(type) {
-	case string:
-		cellHTML = value
+	switch tc.CellValue.(type) {
+	case string, float64, bool:
+		cellHTML = tc.ToText()
 	default:
-		if tc.CellNode != nil {
-			cellHTML = tc.CellNode.ToHTML(false, false)
+		inList := false
+		for _, node := range tc.Nodes {
+			_, isListItem := node.(*ListItem)
+			if isListItem != inList {
+				if isListItem {
+					cellHTML += "<ul>"
+				} else {
+					cellHTML += "</ul>"
+				}
+				inList = isListItem
+			}
+			cellHTML += node.ToHTML(true, true)
+		}
+		if inList {
+			cellHTML += "</ul>"
 		}
 	}
 
@@ -410,6 +599,7 @@ func (tc *TableCell) ToHTML() string {
 type TableRow struct {
 	*Block
 	Cells []*TableCell
+	Type  string `json:"type"`
 }
 
 func NewTableRow(rowJSON map[string]interface{}) *TableRow {
@@ -417,15 +607,19 @@ func NewTableRow(rowJSON map[string]interface{}) *TableRow {
 		Block: NewBlock(rowJSON),
 		Cells: make([]*TableCell, 0),
 	}
+	if rowType, ok := rowJSON["type"].(string); ok {
+		row.Type = rowType
+	}
 
-	if rowType, ok := rowJSON["type"].(string); ok && rowType == "full_row" {
+	if row.IsFullRow() {
 		cell := NewTableCell(rowJSON)
 		row.Cells = append(row.Cells, cell)
 	} else {
 		if cells, ok := rowJSON["cells"].([]interface{}); ok {
 			for _, cellJSON := range cells {
-				cell := NewTableCell(cellJSON.(map[string]interface{}))
-				row.Cells = append(row.Cells, cell)
+				if cellData, ok := cellJSON.(map[string]interface{}); ok {
+					row.Cells = append(row.Cells, NewTableCell(cellData))
+				}
 			}
 		}
 	}
@@ -433,6 +627,12 @@ func NewTableRow(rowJSON map[string]interface{}) *TableRow {
 	return row
 }
 
+// IsFullRow reports whether the row is a single label spanning the table,
+// such as a section label inside a financial statement.
+func (tr *TableRow) IsFullRow() bool {
+	return tr.Type == "full_row"
+}
+
 func (tr *TableRow) ToText(includeChildren, recurse bool) string {
 	cellText := ""
 	for _, cell := range tr.Cells {
@@ -463,8 +663,9 @@ func NewTableHeader(rowJSON map[string]interface{}) *TableHeader {
 
 	if cells, ok := rowJSON["cells"].([]interface{}); ok {
 		for _, cellJSON := range cells {
-			cell := NewTableCell(cellJSON.(map[string]interface{}))
-			header.Cells = append(header.Cells, cell)
+			if cellData, ok := cellJSON.(map[string]interface{}); ok {
+				header.Cells = append(header.Cells, NewTableCell(cellData))
+			}
 		}
 	}
 
@@ -497,6 +698,14 @@ type Table struct {
 	Rows    []*TableRow
 	Headers []*TableHeader
 	Name    string `json:"name"`
+	// Fragments lists the original table blocks when the table was merged
+	// from pieces split across pages, see Document.MergeSplitTables.
+	Fragments []TableFragment
+	// Caption is the header or paragraph introducing the table, UnitNote a
+	// note such as "(In millions)" and Footnotes the notes below the table.
+	Caption   BlockInterface
+	UnitNote  string
+	Footnotes []BlockInterface
 }
 
 func NewTable(tableJSON map[string]interface{}, parent BlockInterface) *Table {
@@ -504,12 +713,17 @@ func NewTable(tableJSON map[string]interface{}, parent BlockInterface) *Table {
 		Block:   NewBlock(tableJSON),
 		Rows:    make([]*TableRow, 0),
 		Headers: make([]*TableHeader, 0),
-		Name:    tableJSON["name"].(string),
+	}
+	if name, ok := tableJSON["name"].(string); ok {
+		table.Name = name
 	}
 
 	if tableRows, ok := tableJSON["table_rows"].([]interface{}); ok {
 		for _, rowJSON := range tableRows {
-			rowData := rowJSON.(map[string]interface{})
+			rowData, ok := rowJSON.(map[string]interface{})
+			if !ok {
+				continue
+			}
 			if rowType, ok := rowData["type"].(string); ok && rowType == "table_header" {
 				header := NewTableHeader(rowData)
 				table.Headers = append(table.Headers, header)
@@ -519,10 +733,34 @@ func NewTable(tableJSON map[string]interface{}, parent BlockInterface) *Table {
 			}
 		}
 	}
+	table.node = table
+	table.adoptCellNodes()
+	table.linkCaption(parent)
 
 	return table
 }
 
+// adoptCellNodes makes t the parent of the blocks nested in its cells, so
+// that their ancestors reach the table and the section holding it.
+func (t *Table) adoptCellNodes() {
+	adopt := func(cells []*TableCell) {
+		for _, cell := range cells {
+			for _, node := range cell.Nodes {
+				if child := blockOf(node); child != nil {
+					child.Parent = t
+					child.setDepth(t.depth + 1)
+				}
+			}
+		}
+	}
+	for _, header := range t.Headers {
+		adopt(header.Cells)
+	}
+	for _, row := range t.Rows {
+		adopt(row.Cells)
+	}
+}
+
 func (t *Table) ToText(includeChildren, recurse bool) string {
 	text := ""
 	for _, header := range t.Headers {
@@ -548,23 +786,11 @@ func (t *Table) ToHTML(includeChildren, recurse bool) string {
 
 type LayoutReader struct{}
 
+// Debug prints the tree below pdfRoot to standard output.
+//
+// Deprecated: Use Dump, which writes to any io.Writer.
 func (lr *LayoutReader) Debug(pdfRoot BlockInterface) {
-	var iterChildren func(node BlockInterface, level int)
-	iterChildren = func(node BlockInterface, level int) {
-		switch nodeBlock := node.(type) {
-		case *Block:
-			for _, child := range nodeBlock.Children {
-				fmt.Printf("%s%s (%d) %s\n", strings.Repeat("-", level), child.(*Block).Tag, len(child.(*Block).Children), child.ToText(false, false))
-				iterChildren(child, level+1)
-			}
-		case *Paragraph, *Section, *ListItem, *Table:
-			for _, child := range nodeBlock.(*Block).Children {
-				fmt.Printf("%s%s (%d) %s\n", strings.Repeat("-", level), child.(*Block).Tag, len(child.(*Block).Children), child.ToText(false, false))
-				iterChildren(child, level+1)
-			}
-		}
-	}
-	iterChildren(pdfRoot, 0)
+	Dump(os.Stdout, pdfRoot, DumpOptions{})
 }
 
 func (lr *LayoutReader) Read(blocksJSON []interface{}) BlockInterface {
@@ -572,7 +798,12 @@ func (lr *LayoutReader) Read(blocksJSON []interface{}) BlockInterface {
 	var parent BlockInterface = rootNode
 	parentStack := []BlockInterface{rootNode}
 	var prevNode BlockInterface = rootNode
+	var prevPrevNode BlockInterface
 	var listStack []BlockInterface
+	// footnoteTable is the table whose footnotes are being collected and
+	// expectFootnote is set after a bare footnote marker such as "(1)".
+	var footnoteTable *Table
+	expectFootnote := false
 
 	for _, blockData := range blocksJSON {
 		blockMap := blockData.(map[string]interface{})
@@ -583,7 +814,11 @@ func (lr *LayoutReader) Read(blocksJSON []interface{}) BlockInterface {
 		case "para":
 			node = NewParagraph(blockMap)
 		case "table":
-			node = NewTable(blockMap, prevNode)
+			table := NewTable(blockMap, prevNode)
+			if isUnitNote(prevNode) {
+				table.linkCaption(prevPrevNode, prevNode)
+			}
+			node = table
 		case "list_item":
 			node = NewListItem(blockMap)
 		case "header":
@@ -602,9 +837,7 @@ func (lr *LayoutReader) Read(blocksJSON []interface{}) BlockInterface {
 			// Check if the last node was a list item and manage list stack accordingly
 			if len(listStack) > 0 {
 				lastListItem := listStack[len(listStack)-1].(*ListItem)
-				if currentLevel > lastListItem.Level {
-					listStack = append(listStack, node)
-				} else {
+				if currentLevel <= lastListItem.Level {
 					// Pop from stack until a node with less or equal level is found
 					for len(listStack) > 0 && listStack[len(listStack)-1].(*ListItem).Level >= currentLevel {
 						listStack = listStack[:len(listStack)-1]
@@ -618,19 +851,15 @@ func (lr *LayoutReader) Read(blocksJSON []interface{}) BlockInterface {
 			}
 			listStack = append(listStack, node)
 		} else {
+			// Any other block ends the list, so a later list item never
+			// nests under one from before it
+			listStack = nil
+
 			// Handling sections with hierarchy
 			if tag == "header" {
-				// Push to parent stack or pop accordingly
-				for len(parentStack) > 0 {
-					topBlock, ok := parentStack[len(parentStack)-1].(*Block)
-					if !ok {
-						// Handle error: topBlock is not of type *Block, which should ideally never happen if the stack is managed correctly
-						fmt.Println("Error: Non-Block type found in parentStack")
-						break
-					}
-					if topBlock.Level < currentLevel {
-						break
-					}
+				// Pop headers at the same or a deeper level, so the new
+				// header nests under the closest shallower one
+				for len(parentStack) > 0 && parentStack[len(parentStack)-1].NodeLevel() >= currentLevel {
 					parentStack = parentStack[:len(parentStack)-1]
 				}
 
@@ -647,6 +876,19 @@ func (lr *LayoutReader) Read(blocksJSON []interface{}) BlockInterface {
 			}
 		}
 
+		if table, ok := node.(*Table); ok {
+			footnoteTable = table
+			expectFootnote = false
+		} else if footnoteTable != nil {
+			if footnote, markerOnly := isFootnote(node); (footnote || expectFootnote) && footnoteTable.mayHoldFootnote(node) {
+				footnoteTable.Footnotes = append(footnoteTable.Footnotes, node)
+				expectFootnote = markerOnly
+			} else {
+				footnoteTable = nil
+			}
+		}
+
+		prevPrevNode = prevNode
 		prevNode = node
 	}
 
@@ -657,6 +899,13 @@ type Document struct {
 	reader   *LayoutReader
 	rootNode BlockInterface
 	json     []interface{}
+	// SourceFile is the name of the PDF the document was parsed from, if known.
+	SourceFile string
+	// NumPages, PageWidth and PageHeight come from the parser response and
+	// are zero when unknown. Page sizes are in PDF points.
+	NumPages   int
+	PageWidth  float64
+	PageHeight float64
 }
 
 func NewDocument(blocksJSON []interface{}) *Document {
@@ -0,0 +1,223 @@
+package chipper
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNodeAccessors(t *testing.T) {
+	doc := sampleDocument(t)
+
+	want := []struct {
+		tag      string
+		level    int
+		page     int
+		blockIdx int
+	}{
+		{"header", 0, 0, 0},
+		{"para", 1, 0, 1},
+		{"list_item", 1, 0, 2},
+		{"table", 1, 1, 3},
+	}
+	var nodes []BlockInterface
+	for node := range doc.All() {
+		nodes = append(nodes, node)
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("expected %d nodes, got %d", len(want), len(nodes))
+	}
+	for i, node := range nodes {
+		if node.NodeTag() != want[i].tag || node.NodeLevel() != want[i].level || node.NodePage() != want[i].page || node.NodeBlockIdx() != want[i].blockIdx {
+			t.Errorf("node %d: got %s level %d page %d block %d", i, node.NodeTag(), node.NodeLevel(), node.NodePage(), node.NodeBlockIdx())
+		}
+		if len(node.NodeBbox()) != 4 {
+			t.Errorf("node %d: expected a bbox, got %v", i, node.NodeBbox())
+		}
+	}
+
+	section := nodes[0]
+	if section.NodeSentences()[0] != "Results of Operations" || len(section.ChildNodes()) != 3 {
+		t.Fatalf("unexpected section %v with %d children", section.NodeSentences(), len(section.ChildNodes()))
+	}
+	for _, child := range section.ChildNodes() {
+		if child.ParentNode() != section {
+			t.Fatalf("%s child has parent %T, expected the section", child.NodeTag(), child.ParentNode())
+		}
+	}
+	if root := section.ParentNode(); root == nil || root.NodeTag() != "" || root.ParentNode() != nil {
+		t.Fatalf("expected the section to hang off the untagged root")
+	}
+	if text := nodes[1].ParentText(); text != " > Results of Operations" {
+		t.Fatalf("unexpected parent text %q", text)
+	}
+}
+
+func TestSectionNesting(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	for _, node := range doc.Sections() {
+		parent := node.ParentNode()
+		if parent.NodeTag() == "header" && parent.NodeLevel() >= node.NodeLevel() {
+			t.Fatalf("section %d at level %d is nested in section %d at level %d",
+				node.NodeBlockIdx(), node.NodeLevel(), parent.NodeBlockIdx(), parent.NodeLevel())
+		}
+	}
+}
+
+func TestHeaderNesting(t *testing.T) {
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "header", "level": 0, "block_idx": 0, "sentences": ["PART I"]},
+		{"tag": "header", "level": 1, "block_idx": 1, "sentences": ["Item 1"]},
+		{"tag": "para", "level": 2, "block_idx": 2, "sentences": ["Body."]},
+		{"tag": "header", "level": 1, "block_idx": 3, "sentences": ["Item 2"]},
+		{"tag": "header", "level": 0, "block_idx": 4, "sentences": ["PART II"]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+
+	// Headers at the same level are siblings and a shallower header closes
+	// the deeper ones, rather than every header nesting in the one before.
+	wantParent := map[int]int{0: -1, 1: 0, 2: 1, 3: 0, 4: -1}
+	for block := range doc.Blocks() {
+		parent := -1
+		if p := block.ParentNode(); p != nil && p.ParentNode() != nil {
+			parent = p.NodeBlockIdx()
+		}
+		if want := wantParent[block.BlockIdx]; parent != want {
+			t.Errorf("block %d: parent %d, want %d", block.BlockIdx, parent, want)
+		}
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	doc := sampleDocument(t)
+	section := doc.Sections()[0]
+	children := section.ChildNodes()
+	para, listItem, table := children[0], children[1], children[2]
+
+	if section.Depth() != 1 || para.Depth() != 2 || section.Index() != 0 || table.Index() != 2 {
+		t.Errorf("unexpected depth or index: section %d/%d, para %d, table index %d",
+			section.Depth(), section.Index(), para.Depth(), table.Index())
+	}
+	if para.NextSibling() != listItem || table.PrevSibling() != listItem {
+		t.Errorf("unexpected siblings of the list item")
+	}
+	if para.PrevSibling() != nil || table.NextSibling() != nil || section.NextSibling() != nil {
+		t.Errorf("expected no siblings past the ends")
+	}
+	// The block just before the table, and the way back to the section.
+	if table.Prev() != listItem || para.Prev() != section || section.Prev() != nil {
+		t.Errorf("unexpected Prev chain")
+	}
+	if section.Next() != para || listItem.Next() != table || table.Next() != nil {
+		t.Errorf("unexpected Next chain")
+	}
+}
+
+// checkNavigation verifies that Next and Prev step through the document in
+// the order of All and that Index and Depth agree with a walk from the root.
+func checkNavigation(t *testing.T, doc *Document) {
+	t.Helper()
+	var nodes []BlockInterface
+	doc.Walk(WalkFunc(func(node BlockInterface, ctx WalkContext) WalkAction {
+		if ctx.Depth > 0 {
+			nodes = append(nodes, node)
+			if node.Depth() != ctx.Depth {
+				t.Errorf("block %d: depth %d, want %d", node.NodeBlockIdx(), node.Depth(), ctx.Depth)
+			}
+			if siblings := ctx.Parent().ChildNodes(); siblings[node.Index()] != node {
+				t.Errorf("block %d: index %d does not point back to it", node.NodeBlockIdx(), node.Index())
+			}
+		}
+		return WalkContinue
+	}))
+	if len(nodes) == 0 {
+		t.Fatal("document has no nodes")
+	}
+	for i, node := range nodes {
+		var next, prev BlockInterface
+		if i+1 < len(nodes) {
+			next = nodes[i+1]
+		}
+		if i > 0 {
+			prev = nodes[i-1]
+		}
+		if node.Next() != next {
+			t.Fatalf("Next of node %d (block %d) is out of document order", i, node.NodeBlockIdx())
+		}
+		if node.Prev() != prev {
+			t.Fatalf("Prev of node %d (block %d) is out of document order", i, node.NodeBlockIdx())
+		}
+	}
+}
+
+func TestNavigationFixture(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	checkNavigation(t, doc)
+}
+
+func TestListNesting(t *testing.T) {
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "list_item", "level": 1, "block_idx": 0, "sentences": ["Offerings"]},
+		{"tag": "list_item", "level": 2, "block_idx": 1, "sentences": ["Mobility"]},
+		{"tag": "list_item", "level": 3, "block_idx": 2, "sentences": ["Rides"]},
+		{"tag": "list_item", "level": 2, "block_idx": 3, "sentences": ["Delivery"]},
+		{"tag": "list_item", "level": 1, "block_idx": 4, "sentences": ["Regions"]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+
+	// A deeper list item is a child of the item above it, and items return
+	// to their own level afterwards; none is ever a child of itself.
+	wantParent := map[int]int{0: -1, 1: 0, 2: 1, 3: 0, 4: -1}
+	for block := range doc.Blocks() {
+		for _, child := range block.Children {
+			if child == block.self() {
+				t.Fatalf("block %d is a child of itself", block.BlockIdx)
+			}
+		}
+		parent := -1
+		if p := block.ParentNode(); p != nil && p.ParentNode() != nil {
+			parent = p.NodeBlockIdx()
+		}
+		if want, ok := wantParent[block.BlockIdx]; !ok || parent != want {
+			t.Errorf("block %d: parent %d, want %d", block.BlockIdx, parent, want)
+		}
+	}
+	if chunks := doc.Chunks(); len(chunks) != 5 {
+		t.Errorf("expected every list item as a chunk, got %d", len(chunks))
+	}
+
+	// A block between list items ends the list: the item after the second
+	// header belongs to that header, not to the item of the first section.
+	blocks = nil
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "header", "level": 1, "block_idx": 0, "sentences": ["Mobility"]},
+		{"tag": "list_item", "level": 1, "block_idx": 1, "sentences": ["Rides"]},
+		{"tag": "header", "level": 1, "block_idx": 2, "sentences": ["Delivery"]},
+		{"tag": "list_item", "level": 1, "block_idx": 3, "sentences": ["Eats"]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc = NewDocument(blocks)
+	checkNavigation(t, doc)
+	wantParent = map[int]int{0: -1, 1: 0, 2: -1, 3: 2}
+	for block := range doc.Blocks() {
+		parent := -1
+		if p := block.ParentNode(); p != nil && p.ParentNode() != nil {
+			parent = p.NodeBlockIdx()
+		}
+		if want, ok := wantParent[block.BlockIdx]; !ok || parent != want {
+			t.Errorf("block %d: parent %d, want %d", block.BlockIdx, parent, want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package chipper
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// EnglishStopwords is the default stopword list of BM25Options.
+var EnglishStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "been": true, "but": true, "by": true, "for": true, "from": true,
+	"had": true, "has": true, "have": true, "he": true, "her": true, "his": true,
+	"if": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+	"no": true, "not": true, "of": true, "on": true, "or": true, "our": true,
+	"she": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "we": true, "were": true, "which": true,
+	"while": true, "who": true, "will": true, "with": true, "would": true,
+	"you": true, "your": true,
+}
+
+type BM25Options struct {
+	// K1 and B are the BM25 saturation and length normalization parameters.
+	// They default to 1.2 and 0.75.
+	K1 float64
+	B  float64
+	// Tokenize splits text into words. Defaults to splitting lowercased text
+	// on anything that is not a letter or digit.
+	Tokenize func(text string) []string
+	// Stopwords are dropped from documents and queries. Defaults to
+	// EnglishStopwords; pass an empty map to keep every word.
+	Stopwords map[string]bool
+	// Stem maps words to index terms. Defaults to StemEnglish; pass a
+	// function returning its input to disable stemming.
+	Stem func(word string) string
+	// HighlightPre and HighlightPost wrap matched words in highlighted
+	// sentences. They default to Markdown bold.
+	HighlightPre  string
+	HighlightPost string
+}
+
+func (o BM25Options) withDefaults() BM25Options {
+	if o.K1 == 0 {
+		o.K1 = 1.2
+	}
+	if o.B == 0 {
+		o.B = 0.75
+	}
+	if o.Tokenize == nil {
+		o.Tokenize = tokenizeWords
+	}
+	if o.Stopwords == nil {
+		o.Stopwords = EnglishStopwords
+	}
+	if o.Stem == nil {
+		o.Stem = StemEnglish
+	}
+	if o.HighlightPre == "" && o.HighlightPost == "" {
+		o.HighlightPre, o.HighlightPost = "**", "**"
+	}
+	return o
+}
+
+func tokenizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// BM25Index is a lexical index over chunk text, complementing VectorIndex for
+// hybrid retrieval.
+type BM25Index struct {
+	opts      BM25Options
+	chunks    []*Chunk
+	termFreqs []map[string]int
+	lengths   []int
+	docFreqs  map[string]int
+	avgLength float64
+	keys      map[string]bool
+}
+
+// NewBM25Index builds an index over chunks, failing as Add does.
+func NewBM25Index(chunks []*Chunk, opts BM25Options) (*BM25Index, error) {
+	ix := &BM25Index{
+		opts:     opts.withDefaults(),
+		docFreqs: make(map[string]int),
+		keys:     make(map[string]bool),
+	}
+	if err := ix.Add(chunks...); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Add indexes chunks. A chunk with the source file and ID of one already
+// indexed is an error, since FuseRRF merges results by that pair; no chunk is
+// added then.
+func (ix *BM25Index) Add(chunks ...*Chunk) error {
+	batch := make(map[string]bool, len(chunks))
+	for _, chunk := range chunks {
+		key := chunkIndexKey(chunk)
+		if ix.keys[key] || batch[key] {
+			return fmt.Errorf("chunk %s of %q is already indexed", chunk.ID, chunk.Metadata.SourceFile)
+		}
+		batch[key] = true
+	}
+
+	total := ix.avgLength * float64(len(ix.chunks))
+	for _, chunk := range chunks {
+		ix.keys[chunkIndexKey(chunk)] = true
+		terms := ix.analyze(chunk.Text)
+		freqs := make(map[string]int)
+		for _, term := range terms {
+			freqs[term]++
+		}
+		for term := range freqs {
+			ix.docFreqs[term]++
+		}
+		ix.chunks = append(ix.chunks, chunk)
+		ix.termFreqs = append(ix.termFreqs, freqs)
+		ix.lengths = append(ix.lengths, len(terms))
+		total += float64(len(terms))
+	}
+	if len(ix.chunks) > 0 {
+		ix.avgLength = total / float64(len(ix.chunks))
+	}
+	return nil
+}
+
+func (ix *BM25Index) Len() int {
+	return len(ix.chunks)
+}
+
+func (ix *BM25Index) analyze(text string) []string {
+	var terms []string
+	for _, word := range ix.opts.Tokenize(text) {
+		if ix.opts.Stopwords[word] {
+			continue
+		}
+		if term := ix.opts.Stem(word); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Search scores every chunk matched by filter against query and returns the
+// k best with at least one matching term. Highlights holds the chunk lines
+// containing a query term with the matching words marked.
+func (ix *BM25Index) Search(query string, k int, filter *SearchFilter) []SearchResult {
+	queryTerms := make(map[string]bool)
+	for _, term := range ix.analyze(query) {
+		queryTerms[term] = true
+	}
+
+	n := float64(len(ix.chunks))
+	var results []SearchResult
+	for i, chunk := range ix.chunks {
+		if !filter.Match(chunk) {
+			continue
+		}
+		score := 0.0
+		for term := range queryTerms {
+			tf := float64(ix.termFreqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(ix.docFreqs[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - ix.opts.B
+			if ix.avgLength > 0 {
+				norm += ix.opts.B * float64(ix.lengths[i]) / ix.avgLength
+			}
+			score += idf * tf * (ix.opts.K1 + 1) / (tf + ix.opts.K1*norm)
+		}
+		if score > 0 {
+			results = append(results, SearchResult{Chunk: chunk, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	for i := range results {
+		results[i].Highlights = ix.highlight(results[i].Chunk.Text, queryTerms)
+	}
+	return results
+}
+
+func (ix *BM25Index) highlight(text string, queryTerms map[string]bool) []string {
+	var highlights []string
+	for _, sentence := range strings.Split(text, "\n") {
+		var b strings.Builder
+		matched := false
+		word := []rune{}
+		flush := func() {
+			if len(word) == 0 {
+				return
+			}
+			original := string(word)
+			isMatch := false
+			for _, token := range ix.opts.Tokenize(original) {
+				if !ix.opts.Stopwords[token] && queryTerms[ix.opts.Stem(token)] {
+					isMatch = true
+				}
+			}
+			if isMatch {
+				matched = true
+				b.WriteString(ix.opts.HighlightPre + original + ix.opts.HighlightPost)
+			} else {
+				b.WriteString(original)
+			}
+			word = word[:0]
+		}
+		for _, r := range sentence {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				word = append(word, r)
+				continue
+			}
+			flush()
+			b.WriteRune(r)
+		}
+		flush()
+		if matched {
+			highlights = append(highlights, strings.TrimSpace(b.String()))
+		}
+	}
+	return highlights
+}
+
+// FuseRRF merges rankings, for example from VectorIndex and BM25Index, with
+// reciprocal rank fusion: every chunk scores the sum of 1/(k+rank) over the
+// rankings it appears in. k defaults to 60 when zero or less.
+func FuseRRF(k int, rankings ...[]SearchResult) []SearchResult {
+	if k <= 0 {
+		k = 60
+	}
+	type fused struct {
+		result SearchResult
+		order  int
+	}
+	byKey := make(map[string]*fused)
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			key := chunkIndexKey(result.Chunk)
+			entry, ok := byKey[key]
+			if !ok {
+				entry = &fused{result: SearchResult{Chunk: result.Chunk}, order: len(byKey)}
+				byKey[key] = entry
+			}
+			entry.result.Score += 1 / float64(k+rank+1)
+			if len(entry.result.Highlights) == 0 {
+				entry.result.Highlights = result.Highlights
+			}
+		}
+	}
+
+	entries := make([]*fused, 0, len(byKey))
+	for _, entry := range byKey {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].result.Score != entries[j].result.Score {
+			return entries[i].result.Score > entries[j].result.Score
+		}
+		return entries[i].order < entries[j].order
+	})
+	results := make([]SearchResult, len(entries))
+	for i, entry := range entries {
+		results[i] = entry.result
+	}
+	return results
+}
@@ -0,0 +1,100 @@
+package chipper
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStemEnglish(t *testing.T) {
+	cases := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"relational":      "relat",
+		"conditional":     "condit",
+		"generalizations": "gener",
+		"running":         "run",
+		"hopping":         "hop",
+		"happy":           "happi",
+		"revenues":        "revenu",
+		"revenue":         "revenu",
+		"agreed":          "agre",
+		"filing":          "file",
+		"adjustment":      "adjust",
+		"2022":            "2022",
+	}
+	for word, want := range cases {
+		if got := StemEnglish(word); got != want {
+			t.Errorf("StemEnglish(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestBM25Index(t *testing.T) {
+	doc := sampleDocument(t)
+	chunks := doc.ChunkRecords(ChunkOptions{})
+	ix, err := NewBM25Index(chunks, BM25Options{})
+	if err != nil {
+		t.Fatalf("NewBM25Index failed: %v", err)
+	}
+
+	results := ix.Search("revenues increasing", 0, nil)
+	if len(results) == 0 {
+		t.Fatal("expected matches for a stemmed query")
+	}
+	if results[0].Chunk.ID != "chunk-2" {
+		t.Fatalf("expected chunk-2 first, got %s", results[0].Chunk.ID)
+	}
+	if len(results[0].Highlights) != 1 || !strings.Contains(results[0].Highlights[0], "**revenue** **increased**") {
+		t.Fatalf("unexpected highlights: %q", results[0].Highlights)
+	}
+
+	if err := ix.Add(chunks[0]); err == nil {
+		t.Fatal("expected an error for a chunk already indexed")
+	}
+	if ix.Len() != len(chunks) {
+		t.Fatalf("rejected chunks were indexed: %d chunks, want %d", ix.Len(), len(chunks))
+	}
+
+	if results := ix.Search("the of and", 0, nil); len(results) != 0 {
+		t.Fatalf("expected stopword-only query to match nothing, got %d results", len(results))
+	}
+	if results := ix.Search("Freight", 0, &SearchFilter{Tags: []string{"para"}}); len(results) != 0 {
+		t.Fatalf("expected the filter to exclude the table, got %d results", len(results))
+	}
+
+	t.Run("RRF", func(t *testing.T) {
+		embedder := NewHashEmbedder(64)
+		if err := EmbedChunks(context.Background(), embedder, chunks, EmbedOptions{}); err != nil {
+			t.Fatalf("EmbedChunks failed: %v", err)
+		}
+		vectors := NewVectorIndex()
+		if err := vectors.Add(chunks...); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		vectorResults, err := vectors.SearchText(context.Background(), embedder, "Freight revenue", 0, nil)
+		if err != nil {
+			t.Fatalf("SearchText failed: %v", err)
+		}
+		lexical := ix.Search("Freight revenue", 0, nil)
+		fused := FuseRRF(0, vectorResults, lexical)
+		if len(fused) != len(chunks) {
+			t.Fatalf("expected %d fused results, got %d", len(chunks), len(fused))
+		}
+		for i := 1; i < len(fused); i++ {
+			if fused[i-1].Score < fused[i].Score {
+				t.Fatal("fused results are not sorted by score")
+			}
+		}
+		for _, result := range fused {
+			if result.Chunk.ID == lexical[0].Chunk.ID && len(result.Highlights) == 0 {
+				t.Fatal("expected fused results to keep lexical highlights")
+			}
+		}
+
+		fused = FuseRRF(0, lexical, lexical)
+		if fused[0].Chunk.ID != lexical[0].Chunk.ID || fused[0].Score != 2.0/61 {
+			t.Fatalf("unexpected fusion of identical rankings: %s scored %f", fused[0].Chunk.ID, fused[0].Score)
+		}
+	})
+}
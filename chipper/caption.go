@@ -0,0 +1,160 @@
+package chipper
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	unitNotePattern = regexp.MustCompile(`(?i)\([^()]*\bin (thousands|millions|billions)\b[^()]*\)`)
+	footnotePattern = regexp.MustCompile(`^(\*+|†|‡|[¹²³⁴⁵⁶⁷⁸⁹]|Notes?:)`)
+	// footnoteNumber matches numbered footnote markers, which the parser sets
+	// apart as blocks of their own since they are printed in a smaller font.
+	// With text following, "(a) The Company…" is a list item of the body.
+	footnoteNumber = regexp.MustCompile(`^(\(\d{1,2}\)|\([a-z]\))$`)
+)
+
+// maxCaptionRunes and maxFootnoteRunes bound the text of a paragraph taken as
+// a table caption and of a table footnote, so that body text following the
+// same patterns is left alone.
+const (
+	maxCaptionRunes  = 300
+	maxFootnoteRunes = 1000
+)
+
+func nodeText(node BlockInterface) string {
+	return strings.Join(strings.Fields(node.ToText(false, false)), " ")
+}
+
+// isUnitNote reports whether node is a stand-alone unit note such as
+// "(In millions, except per share amounts)".
+func isUnitNote(node BlockInterface) bool {
+	if _, ok := node.(*Paragraph); !ok {
+		return false
+	}
+	text := nodeText(node)
+	return strings.HasPrefix(text, "(") && unitNotePattern.FindString(text) != ""
+}
+
+// linkCaption associates the table with the blocks preceding it, given in
+// document order: the closest header, or paragraph that is short or ends with
+// a colon, becomes the caption and a unit note is taken from a stand-alone
+// note, the caption or the name.
+func (t *Table) linkCaption(preceding ...BlockInterface) {
+	t.Caption = nil
+	t.UnitNote = ""
+	for i := len(preceding) - 1; i >= 0; i-- {
+		node := preceding[i]
+		if node == nil {
+			break
+		}
+		if isUnitNote(node) && t.UnitNote == "" {
+			t.UnitNote = unitNotePattern.FindString(nodeText(node))
+			continue
+		}
+		switch node.(type) {
+		case *Section:
+			t.Caption = node
+		case *Paragraph:
+			// A longer paragraph introduces the table only when it
+			// leads into it, as in "…were as follows (in millions):".
+			if text := nodeText(node); utf8.RuneCountInString(text) <= maxCaptionRunes || strings.HasSuffix(text, ":") {
+				t.Caption = node
+			}
+		}
+		break
+	}
+	if t.UnitNote == "" && t.Caption != nil {
+		t.UnitNote = unitNotePattern.FindString(nodeText(t.Caption))
+	}
+	if t.UnitNote == "" {
+		t.UnitNote = unitNotePattern.FindString(t.Name)
+	}
+}
+
+// isFootnote reports whether node opens a table footnote, such as a bare "(1)"
+// or "* Percentage not meaningful.", and whether it is only the marker, with
+// the note text following in the next block.
+func isFootnote(node BlockInterface) (footnote, markerOnly bool) {
+	switch node.(type) {
+	case *Paragraph, *ListItem:
+	default:
+		return false, false
+	}
+	text := nodeText(node)
+	if footnoteNumber.MatchString(text) {
+		return true, true
+	}
+	marker := footnotePattern.FindString(text)
+	return marker != "", marker != "" && marker == text
+}
+
+// mayHoldFootnote reports whether node could be a footnote of the table: a
+// short block on the page of the table.
+func (t *Table) mayHoldFootnote(node BlockInterface) bool {
+	return node.NodePage() == t.PageIdx && utf8.RuneCountInString(nodeText(node)) <= maxFootnoteRunes
+}
+
+// CaptionText returns the caption of the table, or "" when it has none.
+func (t *Table) CaptionText() string {
+	if t.Caption == nil {
+		return ""
+	}
+	return nodeText(t.Caption)
+}
+
+// FootnoteText returns the footnotes below the table, one per line, with
+// stand-alone markers joined to the note they introduce.
+func (t *Table) FootnoteText() string {
+	var lines []string
+	joinNext := false
+	for _, footnote := range t.Footnotes {
+		text := nodeText(footnote)
+		if joinNext && len(lines) > 0 {
+			lines[len(lines)-1] += " " + text
+		} else {
+			lines = append(lines, text)
+		}
+		_, joinNext = isFootnote(footnote)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// captionLines returns the caption, name and unit note lines that introduce
+// the table, skipping any already contained in an earlier line.
+func (t *Table) captionLines() []string {
+	var lines []string
+	for _, line := range []string{t.CaptionText(), strings.TrimSpace(t.Name), t.UnitNote} {
+		if line == "" {
+			continue
+		}
+		repeated := false
+		for _, prev := range lines {
+			repeated = repeated || strings.Contains(strings.ToLower(prev), strings.ToLower(line))
+		}
+		if !repeated {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// withContext surrounds body with the table caption lines and footnotes.
+func (t *Table) withContext(body string) string {
+	text := strings.Join(append(t.captionLines(), body), "\n")
+	if footnotes := t.FootnoteText(); footnotes != "" {
+		text += "\n" + footnotes
+	}
+	return strings.TrimSpace(text)
+}
+
+// ToContextText renders the table with its caption, unit note and footnotes,
+// preceded by the section information when includeSectionInfo is set.
+func (t *Table) ToContextText(includeSectionInfo bool) string {
+	text := ""
+	if includeSectionInfo {
+		text += t.ParentText() + "\n"
+	}
+	return text + t.withContext(t.ToText(true, true))
+}
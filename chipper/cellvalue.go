@@ -0,0 +1,202 @@
+package chipper
+
+import (
+	"strconv"
+	"strings"
+)
+
+type CellKind int
+
+const (
+	// CellEmpty is an empty cell or a dash standing in for zero or n/a.
+	CellEmpty CellKind = iota
+	CellText
+	CellNumber
+	CellCurrency
+	CellPercent
+)
+
+func (k CellKind) String() string {
+	switch k {
+	case CellEmpty:
+		return "empty"
+	case CellText:
+		return "text"
+	case CellNumber:
+		return "number"
+	case CellCurrency:
+		return "currency"
+	case CellPercent:
+		return "percent"
+	}
+	return "unknown"
+}
+
+// CellValue is the typed reading of a table cell such as "$ 1,234", "(567)",
+// "—" or "12.5 %".
+type CellValue struct {
+	Kind CellKind
+	Raw  string
+	// Number is the value as printed, negative for parenthesized amounts.
+	// Percentages keep their printed value, so "12.5 %" is 12.5.
+	Number   float64
+	Currency string
+	// Scale is the multiplier implied by the table title, such as 1e6 for
+	// "(In millions)". It is 1 when unknown and for percentages.
+	Scale float64
+}
+
+func (v CellValue) IsNumeric() bool {
+	return v.Kind == CellNumber || v.Kind == CellCurrency || v.Kind == CellPercent
+}
+
+// Scaled returns Number multiplied by Scale, the amount in base units.
+func (v CellValue) Scaled() float64 {
+	return v.Number * v.Scale
+}
+
+var currencySymbols = []string{"US$", "$", "€", "£", "¥"}
+
+func isDash(s string) bool {
+	switch s {
+	case "-", "—", "–", "−", "--", "n/a", "N/A", "NM", "nm":
+		return true
+	}
+	return false
+}
+
+// ParseCellValue reads the financial value of text with a Scale of 1.
+func ParseCellValue(text string) CellValue {
+	value := CellValue{Kind: CellText, Raw: text, Scale: 1}
+	s := strings.TrimSpace(text)
+	if s == "" || isDash(s) {
+		value.Kind = CellEmpty
+		return value
+	}
+
+	negative := false
+	percent := false
+	currency := ""
+	for changed := true; changed; {
+		changed = true
+		s = strings.TrimSpace(s)
+		switch {
+		case isDash(s):
+			value.Kind = CellEmpty
+			return value
+		case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+			negative = !negative
+			s = s[1 : len(s)-1]
+		case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")%"):
+			negative = !negative
+			percent = true
+			s = s[1 : len(s)-2]
+		case strings.HasSuffix(s, "%"):
+			percent = true
+			s = strings.TrimSuffix(s, "%")
+		case strings.HasPrefix(s, "-"):
+			negative = !negative
+			s = s[1:]
+		case strings.HasPrefix(s, "−"):
+			negative = !negative
+			s = strings.TrimPrefix(s, "−")
+		default:
+			changed = false
+			for _, symbol := range currencySymbols {
+				if strings.HasPrefix(s, symbol) {
+					currency = symbol
+					s = s[len(symbol):]
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" || strings.Trim(s, "0123456789.") != "" {
+		return value
+	}
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return value
+	}
+	if negative {
+		number = -number
+	}
+	value.Number = number
+	value.Currency = currency
+	switch {
+	case percent:
+		value.Kind = CellPercent
+	case currency != "":
+		value.Kind = CellCurrency
+	default:
+		value.Kind = CellNumber
+	}
+	return value
+}
+
+// Value parses the cell text as a financial value, see ParseCellValue.
+func (tc *TableCell) Value() CellValue {
+	return ParseCellValue(tc.ToText())
+}
+
+// ScaleHint looks for unit notes such as "(In millions, except per share
+// amounts)" in the table unit note, caption, name and headers and returns the
+// implied multiplier with the unit word, or 1 and "" when there is none.
+func (t *Table) ScaleHint() (float64, string) {
+	texts := []string{t.UnitNote, t.CaptionText(), t.Name}
+	for _, header := range t.Headers {
+		for _, cell := range header.Cells {
+			texts = append(texts, cell.ToText())
+		}
+	}
+	return scaleHint(texts...)
+}
+
+func scaleHint(texts ...string) (float64, string) {
+	units := []struct {
+		word  string
+		scale float64
+	}{
+		{"thousands", 1e3},
+		{"millions", 1e6},
+		{"billions", 1e9},
+	}
+	for _, text := range texts {
+		lower := strings.ToLower(text)
+		first := -1
+		for i, unit := range units {
+			pos := strings.Index(lower, "in "+unit.word)
+			if pos >= 0 && (first < 0 || pos < strings.Index(lower, "in "+units[first].word)) {
+				first = i
+			}
+		}
+		if first >= 0 {
+			return units[first].scale, units[first].word
+		}
+	}
+	return 1, ""
+}
+
+// ValueAt parses the cell at row and col of Table.Grid, applying the table
+// scale hint to amounts.
+func (t *Table) ValueAt(row, col int) CellValue {
+	grid := t.Grid()
+	if row < 0 || row >= len(grid) || col < 0 || col >= len(grid[row]) {
+		return CellValue{Kind: CellEmpty, Scale: 1}
+	}
+	return t.scaledValue(grid[row][col])
+}
+
+func (t *Table) scaledValue(slot GridCell) CellValue {
+	if !slot.IsOrigin() {
+		return CellValue{Kind: CellEmpty, Scale: 1}
+	}
+	value := slot.Cell.Value()
+	if value.Kind == CellNumber || value.Kind == CellCurrency {
+		value.Scale, _ = t.ScaleHint()
+	}
+	return value
+}
@@ -0,0 +1,56 @@
+package chipper
+
+import "testing"
+
+func TestParseCellValue(t *testing.T) {
+	cases := []struct {
+		text     string
+		kind     CellKind
+		number   float64
+		currency string
+	}{
+		{"$ 1,234", CellCurrency, 1234, "$"},
+		{"(567)", CellNumber, -567, ""},
+		{"$ (5,918)", CellCurrency, -5918, "$"},
+		{"—", CellEmpty, 0, ""},
+		{"$ —", CellEmpty, 0, ""},
+		{"", CellEmpty, 0, ""},
+		{"12.5 %", CellPercent, 12.5, ""},
+		{"(3.2)%", CellPercent, -3.2, ""},
+		{"-42", CellNumber, -42, ""},
+		{"€ 0.85", CellCurrency, 0.85, "€"},
+		{"Revenue", CellText, 0, ""},
+		{"(Unaudited)", CellText, 0, ""},
+		{"1.2.3", CellText, 0, ""},
+	}
+	for _, c := range cases {
+		value := ParseCellValue(c.text)
+		if value.Kind != c.kind || value.Number != c.number || value.Currency != c.currency {
+			t.Errorf("ParseCellValue(%q) = %s %v %q, want %s %v %q", c.text, value.Kind, value.Number, value.Currency, c.kind, c.number, c.currency)
+		}
+		if value.Raw != c.text || value.Scale != 1 {
+			t.Errorf("ParseCellValue(%q) lost its raw text or scale: %+v", c.text, value)
+		}
+	}
+}
+
+func TestTableValueAt(t *testing.T) {
+	table := sampleDocument(t).Tables()[0].(*Table)
+	if scale, unit := table.ScaleHint(); scale != 1e6 || unit != "millions" {
+		t.Fatalf("expected a millions scale hint, got %v %q", scale, unit)
+	}
+
+	value := table.ValueAt(2, 2)
+	if value.Kind != CellCurrency || value.Scaled() != 2518e6 {
+		t.Fatalf("unexpected value at (2, 2): %+v", value)
+	}
+	if value := table.ValueAt(4, 1); value.Number != -26 || value.Scaled() != -26e6 {
+		t.Fatalf("unexpected value at (4, 1): %+v", value)
+	}
+	if value := table.ValueAt(1, 1); value.Kind != CellEmpty {
+		t.Fatalf("expected the spanned slot of a full row to be empty, got %+v", value)
+	}
+	if value := table.ValueAt(99, 0); value.Kind != CellEmpty {
+		t.Fatalf("expected an out of range cell to be empty, got %+v", value)
+	}
+}
@@ -0,0 +1,331 @@
+package chipper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChunkMode controls which records Document.ChunkRecords emits.
+type ChunkMode int
+
+const (
+	// ChunkModeFlat emits one record per paragraph, list item and table,
+	// mirroring Document.Chunks.
+	ChunkModeFlat ChunkMode = iota
+	// ChunkModeHierarchical also emits the enclosing section, list item or
+	// paragraph of every leaf as a parent record linked through ParentID, so a
+	// retrieval hit on a small chunk can be expanded to its surrounding context.
+	ChunkModeHierarchical
+)
+
+// TableChunkStrategy controls how tables are turned into chunks.
+type TableChunkStrategy int
+
+const (
+	// TableChunkWhole emits every table as a single chunk.
+	TableChunkWhole TableChunkStrategy = iota
+	// TableChunkRows splits table rows into pieces that fit ChunkOptions.MaxTokens,
+	// repeating the table name and headers in every piece.
+	TableChunkRows
+)
+
+type ChunkOptions struct {
+	Mode          ChunkMode
+	TableStrategy TableChunkStrategy
+	MaxTokens     int
+	Tokenizer     Tokenizer
+}
+
+type Chunk struct {
+	// ID is unique within a document and built from the block index, such as
+	// "chunk-12" or "parent-3"; blocks the parser left without one use their
+	// position in document order instead, as "chunk-n40". Chunks of different
+	// documents are told apart by Metadata.SourceFile together with ID.
+	ID          string
+	ParentID    string
+	ChildIDs    []string
+	IsParent    bool
+	Node        BlockInterface
+	Text        string
+	ContextText string
+	// RowStart and RowEnd delimit the half-open range of Table.Rows covered
+	// by a table chunk.
+	RowStart  int
+	RowEnd    int
+	Metadata  ChunkMetadata
+	Embedding []float32
+}
+
+type ChunkMetadata struct {
+	Tag         string    `json:"tag"`
+	Pages       []int     `json:"pages"`
+	Bbox        []float64 `json:"bbox,omitempty"`
+	SectionPath []string  `json:"section_path"`
+	SourceFile  string    `json:"source_file,omitempty"`
+}
+
+// chunkMetadata describes node found below path. Parent records span their
+// whole subtree, so their pages include those of every descendant.
+func chunkMetadata(node BlockInterface, path []BlockInterface, subtree bool, sourceFile string) ChunkMetadata {
+	block := blockOf(node)
+	meta := ChunkMetadata{
+		Tag:         block.Tag,
+		Bbox:        block.Bbox,
+		SectionPath: []string{},
+		SourceFile:  sourceFile,
+	}
+	for _, p := range path {
+		if section, ok := p.(*Section); ok {
+			meta.SectionPath = append(meta.SectionPath, section.Title)
+		}
+	}
+	if section, ok := node.(*Section); ok && subtree {
+		meta.SectionPath = append(meta.SectionPath, section.Title)
+	}
+
+	pages := map[int]bool{}
+	if block.PageIdx >= 0 {
+		pages[block.PageIdx] = true
+	}
+	if table, ok := node.(*Table); ok {
+		for _, pageIdx := range table.Pages() {
+			if pageIdx >= 0 {
+				pages[pageIdx] = true
+			}
+		}
+	}
+	if subtree {
+		Walk(node, WalkFunc(func(child BlockInterface, _ WalkContext) WalkAction {
+			if pageIdx := blockOf(child).PageIdx; pageIdx >= 0 {
+				pages[pageIdx] = true
+			}
+			return WalkContinue
+		}))
+	}
+	meta.Pages = make([]int, 0, len(pages))
+	for pageIdx := range pages {
+		meta.Pages = append(meta.Pages, pageIdx)
+	}
+	sort.Ints(meta.Pages)
+	return meta
+}
+
+func leafChunkID(node BlockInterface, order map[BlockInterface]int) string {
+	return "chunk-" + chunkKey(node, order)
+}
+
+func parentChunkID(node BlockInterface, order map[BlockInterface]int) string {
+	return "parent-" + chunkKey(node, order)
+}
+
+// chunkKey identifies node by its block index, or by its position in order
+// when it has none, so that chunk IDs stay unique within a document.
+func chunkKey(node BlockInterface, order map[BlockInterface]int) string {
+	if blockIdx := blockOf(node).BlockIdx; blockIdx >= 0 {
+		return fmt.Sprint(blockIdx)
+	}
+	return fmt.Sprintf("n%d", order[node])
+}
+
+// blockOf returns the Block embedded in any node type of the tree.
+func blockOf(node BlockInterface) *Block {
+	if based, ok := node.(interface{ base() *Block }); ok {
+		return based.base()
+	}
+	return nil
+}
+
+func childrenOf(node BlockInterface) []BlockInterface {
+	if b := blockOf(node); b != nil {
+		return b.Children
+	}
+	return nil
+}
+
+func isLeafChunk(node BlockInterface) bool {
+	switch node.(type) {
+	case *Paragraph, *ListItem, *Table:
+		return true
+	}
+	return false
+}
+
+func isParentChunk(node BlockInterface) bool {
+	switch node.(type) {
+	case *Section, *Paragraph, *ListItem:
+		return true
+	}
+	return false
+}
+
+// pathText renders the section titles and enclosing paragraphs of path in the
+// same layout as Block.ParentText.
+func pathText(path []BlockInterface) string {
+	var headerTexts, paraTexts []string
+	for _, p := range path {
+		switch pathNode := p.(type) {
+		case *Section:
+			headerTexts = append(headerTexts, pathNode.Title)
+		case *Paragraph, *ListItem:
+			paraTexts = append(paraTexts, pathNode.ToText(false, false))
+		}
+	}
+	text := ""
+	if len(headerTexts) > 0 {
+		text += " > " + strings.Join(headerTexts, " > ")
+	}
+	if len(paraTexts) > 0 {
+		text += "\n" + strings.Join(paraTexts, "\n")
+	}
+	return text
+}
+
+// ChunkRecords splits the document into retrieval chunks. Leaf chunks hold the
+// text of a single paragraph, list item or table; in hierarchical mode each
+// leaf links to a parent record holding the full text of its enclosing node.
+func (d *Document) ChunkRecords(opts ChunkOptions) []*Chunk {
+	var chunks []*Chunk
+	parents := make(map[BlockInterface]*Chunk)
+	order := make(map[BlockInterface]int)
+	for node := range d.All() {
+		order[node] = len(order)
+	}
+
+	var parentRecord func(path []BlockInterface, i int) *Chunk
+	parentRecord = func(path []BlockInterface, i int) *Chunk {
+		node := path[i]
+		if record, ok := parents[node]; ok {
+			return record
+		}
+		text := node.ToText(true, true)
+		record := &Chunk{
+			ID:          parentChunkID(node, order),
+			IsParent:    true,
+			Node:        node,
+			Text:        text,
+			ContextText: strings.TrimSpace(pathText(path[:i]) + "\n" + text),
+			Metadata:    chunkMetadata(node, path[:i], true, d.SourceFile),
+		}
+		for j := i - 1; j >= 0; j-- {
+			if isParentChunk(path[j]) {
+				grandparent := parentRecord(path, j)
+				record.ParentID = grandparent.ID
+				grandparent.ChildIDs = append(grandparent.ChildIDs, record.ID)
+				break
+			}
+		}
+		parents[node] = record
+		chunks = append(chunks, record)
+		return record
+	}
+
+	d.Walk(WalkFunc(func(node BlockInterface, ctx WalkContext) WalkAction {
+		if !isLeafChunk(node) {
+			return WalkContinue
+		}
+		path := ctx.Path
+		var leaves []*Chunk
+		if table, ok := node.(*Table); ok && opts.TableStrategy == TableChunkRows {
+			for _, piece := range table.SplitRows(opts.MaxTokens, opts.Tokenizer) {
+				metadata := chunkMetadata(node, path, false, d.SourceFile)
+				if len(table.Fragments) > 0 {
+					metadata.Pages = table.rowPages(piece.RowStart, piece.RowEnd)
+				}
+				leaves = append(leaves, &Chunk{
+					ID:          fmt.Sprintf("%s-rows-%d-%d", leafChunkID(node, order), piece.RowStart, piece.RowEnd),
+					Node:        node,
+					Text:        piece.Text,
+					ContextText: strings.TrimSpace(pathText(path) + "\n" + piece.Text),
+					Metadata:    metadata,
+					RowStart:    piece.RowStart,
+					RowEnd:      piece.RowEnd,
+				})
+			}
+		} else {
+			text := node.ToText(false, false)
+			if table, ok := node.(*Table); ok {
+				text = table.withContext(text)
+			}
+			chunk := &Chunk{
+				ID:          leafChunkID(node, order),
+				Node:        node,
+				Text:        text,
+				ContextText: strings.TrimSpace(pathText(path) + "\n" + text),
+				Metadata:    chunkMetadata(node, path, false, d.SourceFile),
+			}
+			if table, ok := node.(*Table); ok {
+				chunk.RowEnd = len(table.Rows)
+			}
+			leaves = append(leaves, chunk)
+		}
+		if opts.Mode == ChunkModeHierarchical {
+			for i := len(path) - 1; i >= 0; i-- {
+				if isParentChunk(path[i]) {
+					parent := parentRecord(path, i)
+					for _, leaf := range leaves {
+						leaf.ParentID = parent.ID
+						parent.ChildIDs = append(parent.ChildIDs, leaf.ID)
+					}
+					break
+				}
+			}
+		}
+		chunks = append(chunks, leaves...)
+		return WalkContinue
+	}))
+
+	return chunks
+}
+
+type TablePiece struct {
+	RowStart int
+	RowEnd   int
+	Text     string
+}
+
+// SplitRows groups the table rows into pieces of at most maxTokens tokens. Each
+// piece repeats the table caption, name, unit note, headers and footnotes; a
+// single row that exceeds the budget on its own still gets a piece of its own.
+// A maxTokens of zero or less keeps all rows in one piece, and a nil tokenizer
+// uses DefaultTokenizer.
+func (t *Table) SplitRows(maxTokens int, tokenizer Tokenizer) []TablePiece {
+	if tokenizer == nil {
+		tokenizer = DefaultTokenizer
+	}
+	prefix := ""
+	for _, line := range t.captionLines() {
+		prefix += line + "\n"
+	}
+	for _, header := range t.Headers {
+		prefix += header.ToText(false, false) + "\n"
+	}
+	suffix := ""
+	if footnotes := t.FootnoteText(); footnotes != "" {
+		suffix = footnotes + "\n"
+	}
+
+	var pieces []TablePiece
+	start := 0
+	text := prefix
+	for i, row := range t.Rows {
+		rowText := row.ToText(false, false) + "\n"
+		if maxTokens > 0 && i > start && tokenizer.CountTokens(text+rowText+suffix) > maxTokens {
+			pieces = append(pieces, TablePiece{RowStart: start, RowEnd: i, Text: strings.TrimSpace(text + suffix)})
+			start = i
+			text = prefix
+		}
+		text += rowText
+	}
+	pieces = append(pieces, TablePiece{RowStart: start, RowEnd: len(t.Rows), Text: strings.TrimSpace(text + suffix)})
+	return pieces
+}
+
+// ChunkLookup indexes chunks by ID so a hit can be expanded via ParentID.
+func ChunkLookup(chunks []*Chunk) map[string]*Chunk {
+	lookup := make(map[string]*Chunk, len(chunks))
+	for _, chunk := range chunks {
+		lookup[chunk.ID] = chunk
+	}
+	return lookup
+}
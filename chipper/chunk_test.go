@@ -0,0 +1,146 @@
+package chipper
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestChunkRecords(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+
+	t.Run("Flat", func(t *testing.T) {
+		chunks := doc.ChunkRecords(ChunkOptions{})
+		if len(chunks) != len(doc.Chunks()) {
+			t.Fatalf("expected %d chunks, got %d", len(doc.Chunks()), len(chunks))
+		}
+		for _, chunk := range chunks {
+			if chunk.IsParent || chunk.ParentID != "" {
+				t.Fatalf("flat chunk %s should not link to a parent", chunk.ID)
+			}
+		}
+	})
+
+	t.Run("Hierarchical", func(t *testing.T) {
+		chunks := doc.ChunkRecords(ChunkOptions{Mode: ChunkModeHierarchical})
+		lookup := ChunkLookup(chunks)
+		if len(lookup) != len(chunks) {
+			t.Fatalf("chunk IDs are not unique: %d IDs for %d chunks", len(lookup), len(chunks))
+		}
+
+		leaves := 0
+		for _, chunk := range chunks {
+			if !chunk.IsParent {
+				leaves++
+			}
+			if chunk.ParentID == "" {
+				continue
+			}
+			parent, ok := lookup[chunk.ParentID]
+			if !ok {
+				t.Fatalf("chunk %s links to missing parent %s", chunk.ID, chunk.ParentID)
+			}
+			if !parent.IsParent {
+				t.Fatalf("chunk %s links to non-parent record %s", chunk.ID, parent.ID)
+			}
+			found := false
+			for _, id := range parent.ChildIDs {
+				found = found || id == chunk.ID
+			}
+			if !found {
+				t.Fatalf("parent %s does not list child %s", parent.ID, chunk.ID)
+			}
+			if !strings.Contains(parent.Text, chunk.Node.ToText(false, false)) {
+				t.Fatalf("parent %s text does not contain child %s", parent.ID, chunk.ID)
+			}
+		}
+		if leaves != len(doc.Chunks()) {
+			t.Fatalf("expected %d leaf chunks, got %d", len(doc.Chunks()), leaves)
+		}
+	})
+}
+
+func TestTableRowChunks(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+
+	const maxTokens = 120
+	chunks := doc.ChunkRecords(ChunkOptions{TableStrategy: TableChunkRows, MaxTokens: maxTokens})
+	pieces := make(map[*Table][]*Chunk)
+	for _, chunk := range chunks {
+		if table, ok := chunk.Node.(*Table); ok {
+			pieces[table] = append(pieces[table], chunk)
+		}
+	}
+	if len(pieces) != len(doc.Tables()) {
+		t.Fatalf("expected pieces for %d tables, got %d", len(doc.Tables()), len(pieces))
+	}
+
+	// A nil tokenizer falls back to DefaultTokenizer as in ChunkOptions.
+	for table := range pieces {
+		withNil, withDefault := table.SplitRows(maxTokens, nil), table.SplitRows(maxTokens, DefaultTokenizer)
+		if len(withNil) != len(withDefault) || withNil[len(withNil)-1] != withDefault[len(withDefault)-1] {
+			t.Fatalf("table %d: SplitRows with a nil tokenizer differs from DefaultTokenizer", table.BlockIdx)
+		}
+	}
+
+	split := 0
+	for table, tableChunks := range pieces {
+		next := 0
+		for _, chunk := range tableChunks {
+			if chunk.RowStart != next || chunk.RowEnd < chunk.RowStart {
+				t.Fatalf("table %d: piece %s covers rows [%d, %d), expected start %d", table.BlockIdx, chunk.ID, chunk.RowStart, chunk.RowEnd, next)
+			}
+			next = chunk.RowEnd
+			if caption := strings.Join(table.captionLines(), "\n"); !strings.HasPrefix(chunk.Text, caption) {
+				t.Fatalf("table %d: piece %s does not start with the table caption and name", table.BlockIdx, chunk.ID)
+			}
+			for _, header := range table.Headers {
+				if !strings.Contains(chunk.Text, header.ToText(false, false)) {
+					t.Fatalf("table %d: piece %s is missing a header", table.BlockIdx, chunk.ID)
+				}
+			}
+			if chunk.RowEnd-chunk.RowStart > 1 && DefaultTokenizer.CountTokens(chunk.Text) > maxTokens {
+				t.Fatalf("table %d: piece %s exceeds the token budget", table.BlockIdx, chunk.ID)
+			}
+		}
+		if next != len(table.Rows) {
+			t.Fatalf("table %d: pieces cover %d of %d rows", table.BlockIdx, next, len(table.Rows))
+		}
+		if len(tableChunks) > 1 {
+			split++
+		}
+	}
+	if split == 0 {
+		t.Fatal("expected at least one table to be split")
+	}
+}
+
+func TestChunkIDsWithoutBlockIdx(t *testing.T) {
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "header", "level": 0, "sentences": ["Overview"]},
+		{"tag": "para", "level": 1, "sentences": ["First paragraph."]},
+		{"tag": "para", "level": 1, "sentences": ["Second paragraph."]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+
+	chunks := doc.ChunkRecords(ChunkOptions{Mode: ChunkModeHierarchical})
+	seen := map[string]bool{}
+	for _, chunk := range chunks {
+		if seen[chunk.ID] {
+			t.Fatalf("duplicate chunk ID %s", chunk.ID)
+		}
+		seen[chunk.ID] = true
+	}
+	if !seen["parent-n0"] || !seen["chunk-n1"] || !seen["chunk-n2"] {
+		t.Fatalf("expected IDs in document order, got %v", seen)
+	}
+}
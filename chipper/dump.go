@@ -0,0 +1,131 @@
+package chipper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type DumpOptions struct {
+	// Indent is repeated once per level of depth, two spaces by default.
+	Indent string
+	// MaxTextLen truncates the text of every node to this many runes, 60 by
+	// default; a negative value leaves the text out.
+	MaxTextLen int
+	// MaxDepth stops the dump below this depth, counting top-level nodes as
+	// depth 1; zero dumps the whole tree.
+	MaxDepth int
+	// JSON writes the tree as indented JSON instead of one line per node.
+	JSON bool
+}
+
+// dumpNode is the JSON form of a node written by Dump.
+type dumpNode struct {
+	Tag         string      `json:"tag"`
+	Level       int         `json:"level"`
+	Page        int         `json:"page"`
+	BlockIdx    int         `json:"block_idx"`
+	NumChildren int         `json:"num_children"`
+	Text        string      `json:"text,omitempty"`
+	Children    []*dumpNode `json:"children,omitempty"`
+}
+
+// Dump writes the tree below node, one line per node indented by depth with
+// its tag, level, page, block index, number of children and truncated text,
+// or as JSON. The untagged root of a document is not written itself.
+func Dump(w io.Writer, node BlockInterface, opts DumpOptions) error {
+	indent := opts.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	maxTextLen := opts.MaxTextLen
+	if maxTextLen == 0 {
+		maxTextLen = 60
+	}
+	// The untagged root is skipped, putting its children at depth 1.
+	rootOffset := 0
+	if node != nil && node.NodeTag() == "" && node.ParentNode() == nil {
+		rootOffset = 1
+	}
+
+	var b strings.Builder
+	var stack []*dumpNode
+	var roots []*dumpNode
+	Walk(node, VisitorFuncs{
+		OnEnter: func(n BlockInterface, ctx WalkContext) WalkAction {
+			depth := ctx.Depth + 1 - rootOffset
+			if depth == 0 {
+				return WalkContinue
+			}
+			if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+				return WalkSkipChildren
+			}
+			text := ""
+			if maxTextLen > 0 {
+				text = truncateText(dumpText(n), maxTextLen)
+			}
+			if !opts.JSON {
+				b.WriteString(strings.Repeat(indent, depth-1))
+				fmt.Fprintf(&b, "%s level=%d page=%d block=%d children=%d", n.NodeTag(), n.NodeLevel(), n.NodePage(), n.NodeBlockIdx(), len(n.ChildNodes()))
+				if table, ok := n.(*Table); ok {
+					fmt.Fprintf(&b, " rows=%d cols=%d", len(table.Rows), table.NumCols())
+				}
+				if text != "" {
+					fmt.Fprintf(&b, " %q", text)
+				}
+				b.WriteString("\n")
+				return WalkContinue
+			}
+			entry := &dumpNode{
+				Tag:         n.NodeTag(),
+				Level:       n.NodeLevel(),
+				Page:        n.NodePage(),
+				BlockIdx:    n.NodeBlockIdx(),
+				NumChildren: len(n.ChildNodes()),
+				Text:        text,
+			}
+			if len(stack) == 0 {
+				roots = append(roots, entry)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, entry)
+			}
+			stack = append(stack, entry)
+			return WalkContinue
+		},
+		OnLeave: func(n BlockInterface, ctx WalkContext) WalkAction {
+			depth := ctx.Depth + 1 - rootOffset
+			if opts.JSON && depth > 0 && (opts.MaxDepth == 0 || depth <= opts.MaxDepth) {
+				stack = stack[:len(stack)-1]
+			}
+			return WalkContinue
+		},
+	})
+
+	if opts.JSON {
+		if roots == nil {
+			roots = []*dumpNode{}
+		}
+		data, err := json.MarshalIndent(roots, "", indent)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Dump writes the document tree, see Dump.
+func (d *Document) Dump(w io.Writer, opts DumpOptions) error {
+	return Dump(w, d.rootNode, opts)
+}
+
+func dumpText(node BlockInterface) string {
+	if section, ok := node.(*Section); ok {
+		return strings.Join(strings.Fields(section.Title), " ")
+	}
+	return nodeText(node)
+}
@@ -0,0 +1,93 @@
+package chipper
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	doc := sampleDocument(t)
+
+	var buf bytes.Buffer
+	if err := doc.Dump(&buf, DumpOptions{MaxTextLen: 20}); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	want := `header level=0 page=0 block=0 children=3 "Results of Operatio…"
+  para level=1 page=0 block=1 children=0 "Revenue grew in the…"
+  list_item level=1 page=0 block=2 children=0 "Mobility revenue in…"
+  table level=1 page=1 block=3 children=0 rows=4 cols=3 "| | 2021 | 2022 | -…"
+`
+	if buf.String() != want {
+		t.Errorf("Dump output mismatch:\nGot:\n%s\nWant:\n%s", buf.String(), want)
+	}
+
+	// Dumping a subtree writes the node itself at the top.
+	buf.Reset()
+	if err := Dump(&buf, doc.Tables()[0], DumpOptions{Indent: "\t", MaxTextLen: -1}); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	if got := buf.String(); got != "table level=1 page=1 block=3 children=0 rows=4 cols=3\n" {
+		t.Errorf("unexpected subtree dump %q", got)
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	doc := sampleDocument(t)
+
+	var buf bytes.Buffer
+	if err := doc.Dump(&buf, DumpOptions{JSON: true}); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	var nodes []dumpNode
+	if err := json.Unmarshal(buf.Bytes(), &nodes); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(nodes) != 1 || nodes[0].Tag != "header" || nodes[0].Text != "Results of Operations" {
+		t.Fatalf("unexpected top-level nodes %+v", nodes)
+	}
+	if len(nodes[0].Children) != 3 || nodes[0].Children[2].Tag != "table" || nodes[0].Children[2].Page != 1 {
+		t.Errorf("unexpected children %+v", nodes[0].Children)
+	}
+
+	// MaxDepth keeps the child count of the nodes it cuts off.
+	buf.Reset()
+	if err := doc.Dump(&buf, DumpOptions{JSON: true, MaxDepth: 1}); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	nodes = nil
+	if err := json.Unmarshal(buf.Bytes(), &nodes); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(nodes) != 1 || len(nodes[0].Children) != 0 || nodes[0].NumChildren != 3 {
+		t.Errorf("unexpected depth-limited dump %+v", nodes)
+	}
+}
+
+func TestDumpFixture(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	// The fixture mixes sections, paragraphs, list items and tables at every
+	// level, which the former Debug could not print without panicking.
+	var buf bytes.Buffer
+	if err := doc.Dump(&buf, DumpOptions{}); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	count := 0
+	for range doc.All() {
+		count++
+	}
+	if len(lines) != count {
+		t.Errorf("expected one line per node, got %d lines for %d nodes", len(lines), count)
+	}
+	for _, tag := range []string{"header ", "para ", "list_item ", "table "} {
+		if !strings.Contains(buf.String(), "\n"+tag) && !strings.Contains(buf.String(), " "+tag) {
+			t.Errorf("dump has no %q line", tag)
+		}
+	}
+}
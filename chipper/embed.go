@@ -0,0 +1,224 @@
+package chipper
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Embedder turns a batch of texts into one vector per text, in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+type EmbedOptions struct {
+	// ChunkOptions is used by Document.EmbedChunks to chunk the document.
+	ChunkOptions ChunkOptions
+	// BatchSize caps the number of texts per Embed call. Defaults to 64.
+	BatchSize int
+	// MaxBatchTokens caps the tokens per Embed call when positive.
+	MaxBatchTokens int
+	Tokenizer      Tokenizer
+	// Concurrency is the number of Embed calls in flight. Defaults to 4.
+	Concurrency int
+	// MaxRetries is the number of retries of a failed batch. Defaults to 3;
+	// a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on every
+	// further attempt. Defaults to 500ms.
+	RetryBackoff time.Duration
+	// UseContextText embeds ContextText instead of Text.
+	UseContextText bool
+}
+
+func (o EmbedOptions) withDefaults() EmbedOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 64
+	}
+	if o.Tokenizer == nil {
+		o.Tokenizer = DefaultTokenizer
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 4
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	} else if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 500 * time.Millisecond
+	}
+	return o
+}
+
+// EmbedChunks chunks the document and attaches an embedding to every chunk.
+func (d *Document) EmbedChunks(ctx context.Context, embedder Embedder, opts EmbedOptions) ([]*Chunk, error) {
+	chunks := d.ChunkRecords(opts.ChunkOptions)
+	if err := EmbedChunks(ctx, embedder, chunks, opts); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// EmbedChunks batches the chunk texts, embeds the batches concurrently and
+// stores the vectors in Chunk.Embedding.
+func EmbedChunks(ctx context.Context, embedder Embedder, chunks []*Chunk, opts EmbedOptions) error {
+	opts = opts.withDefaults()
+	batches := batchChunks(chunks, opts)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for _, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(batch []*Chunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := embedBatch(ctx, embedder, batch, opts); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func chunkEmbedText(chunk *Chunk, opts EmbedOptions) string {
+	if opts.UseContextText {
+		return chunk.ContextText
+	}
+	return chunk.Text
+}
+
+func batchChunks(chunks []*Chunk, opts EmbedOptions) [][]*Chunk {
+	var batches [][]*Chunk
+	var batch []*Chunk
+	batchTokens := 0
+	for _, chunk := range chunks {
+		tokens := 0
+		if opts.MaxBatchTokens > 0 {
+			tokens = opts.Tokenizer.CountTokens(chunkEmbedText(chunk, opts))
+		}
+		if len(batch) > 0 && (len(batch) >= opts.BatchSize || (opts.MaxBatchTokens > 0 && batchTokens+tokens > opts.MaxBatchTokens)) {
+			batches = append(batches, batch)
+			batch = nil
+			batchTokens = 0
+		}
+		batch = append(batch, chunk)
+		batchTokens += tokens
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func embedBatch(ctx context.Context, embedder Embedder, batch []*Chunk, opts EmbedOptions) error {
+	texts := make([]string, len(batch))
+	for i, chunk := range batch {
+		texts[i] = chunkEmbedText(chunk, opts)
+	}
+
+	backoff := opts.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		var vectors [][]float32
+		vectors, err = embedder.Embed(ctx, texts)
+		if err == nil && len(vectors) != len(texts) {
+			err = fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+		}
+		if err == nil {
+			for i, chunk := range batch {
+				chunk.Embedding = vectors[i]
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("embedding batch of %d chunks: %w", len(batch), err)
+}
+
+// HashEmbedder is a deterministic Embedder that hashes lowercased words into
+// a fixed number of dimensions. It needs no model service, which makes it
+// suitable for tests and examples, but carries no semantic meaning.
+type HashEmbedder struct {
+	Dim int
+}
+
+func NewHashEmbedder(dim int) *HashEmbedder {
+	return &HashEmbedder{Dim: dim}
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.Dim <= 0 {
+		return nil, fmt.Errorf("hash embedder dimension must be positive, got %d", e.Dim)
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vector := make([]float32, e.Dim)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			h := fnv.New64a()
+			h.Write([]byte(word))
+			sum := h.Sum64()
+			sign := float32(1)
+			if sum&(1<<63) != 0 {
+				sign = -1
+			}
+			vector[sum%uint64(e.Dim)] += sign
+		}
+		var norm float64
+		for _, v := range vector {
+			norm += float64(v) * float64(v)
+		}
+		if norm > 0 {
+			scale := float32(1 / math.Sqrt(norm))
+			for j := range vector {
+				vector[j] *= scale
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
@@ -0,0 +1,76 @@
+package chipper
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type flakyEmbedder struct {
+	inner    Embedder
+	failures int32
+	calls    int32
+}
+
+func (e *flakyEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if atomic.AddInt32(&e.calls, 1) <= e.failures {
+		return nil, errors.New("temporary failure")
+	}
+	return e.inner.Embed(ctx, texts)
+}
+
+func TestEmbedChunks(t *testing.T) {
+	doc := sampleDocument(t)
+	opts := EmbedOptions{BatchSize: 2, Concurrency: 2, RetryBackoff: time.Millisecond}
+
+	chunks, err := doc.EmbedChunks(context.Background(), NewHashEmbedder(16), opts)
+	if err != nil {
+		t.Fatalf("EmbedChunks failed: %v", err)
+	}
+	for _, chunk := range chunks {
+		if len(chunk.Embedding) != 16 {
+			t.Fatalf("chunk %s has %d dimensions, expected 16", chunk.ID, len(chunk.Embedding))
+		}
+	}
+
+	again, err := doc.EmbedChunks(context.Background(), NewHashEmbedder(16), opts)
+	if err != nil {
+		t.Fatalf("EmbedChunks failed: %v", err)
+	}
+	for i := range chunks {
+		if !reflect.DeepEqual(chunks[i].Embedding, again[i].Embedding) {
+			t.Fatalf("hash embeddings of chunk %s are not deterministic", chunks[i].ID)
+		}
+	}
+
+	t.Run("Retries", func(t *testing.T) {
+		embedder := &flakyEmbedder{inner: NewHashEmbedder(8), failures: 2}
+		chunks := doc.ChunkRecords(ChunkOptions{})
+		if err := EmbedChunks(context.Background(), embedder, chunks, EmbedOptions{Concurrency: 1, RetryBackoff: time.Millisecond}); err != nil {
+			t.Fatalf("EmbedChunks failed after retries: %v", err)
+		}
+		if chunks[0].Embedding == nil {
+			t.Fatal("expected embeddings after retries")
+		}
+	})
+
+	t.Run("GivesUp", func(t *testing.T) {
+		embedder := &flakyEmbedder{inner: NewHashEmbedder(8), failures: 100}
+		chunks := doc.ChunkRecords(ChunkOptions{})
+		err := EmbedChunks(context.Background(), embedder, chunks, EmbedOptions{MaxRetries: 1, RetryBackoff: time.Millisecond})
+		if err == nil {
+			t.Fatal("expected an error once retries are exhausted")
+		}
+	})
+
+	t.Run("Batching", func(t *testing.T) {
+		chunks := doc.ChunkRecords(ChunkOptions{})
+		batches := batchChunks(chunks, EmbedOptions{BatchSize: 10, MaxBatchTokens: 12}.withDefaults())
+		if len(batches) < 2 {
+			t.Fatalf("expected the token limit to split %d chunks into several batches, got %d", len(chunks), len(batches))
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package chipper
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ChunkJSON is the schema of one line written by WriteChunksJSONL:
+//
+//	{
+//	  "id": "chunk-12",                 // stable chunk ID
+//	  "parent_id": "parent-9",          // enclosing parent record, omitted at top level
+//	  "is_parent": false,               // true for hierarchical parent records
+//	  "text": "...",                    // chunk text to embed
+//	  "context_text": "...",            // section path followed by the chunk text
+//	  "row_start": 0, "row_end": 12,    // Table.Rows covered, tables only
+//	  "metadata": {
+//	    "tag": "para",                  // para, list_item, table or header
+//	    "pages": [3, 4],                // zero-based page indices
+//	    "bbox": [x0, y0, x1, y1],       // omitted when the parser gave none
+//	    "section_path": ["PART I", "ITEM 2. ..."],
+//	    "source_file": "uber_10q.pdf"   // omitted when unknown
+//	  },
+//	  "embedding": [0.1, ...]           // only with JSONLOptions.IncludeEmbeddings
+//	}
+type ChunkJSON struct {
+	ID          string        `json:"id"`
+	ParentID    string        `json:"parent_id,omitempty"`
+	IsParent    bool          `json:"is_parent"`
+	Text        string        `json:"text"`
+	ContextText string        `json:"context_text"`
+	RowStart    *int          `json:"row_start,omitempty"`
+	RowEnd      *int          `json:"row_end,omitempty"`
+	Metadata    ChunkMetadata `json:"metadata"`
+	Embedding   []float32     `json:"embedding,omitempty"`
+}
+
+type JSONLOptions struct {
+	// Chunks to write. When nil the document is chunked with ChunkOptions.
+	Chunks       []*Chunk
+	ChunkOptions ChunkOptions
+	// SourceFile overrides Document.SourceFile in the metadata when set.
+	SourceFile        string
+	IncludeEmbeddings bool
+}
+
+func NewChunkJSON(chunk *Chunk, includeEmbedding bool) ChunkJSON {
+	record := ChunkJSON{
+		ID:          chunk.ID,
+		ParentID:    chunk.ParentID,
+		IsParent:    chunk.IsParent,
+		Text:        chunk.Text,
+		ContextText: chunk.ContextText,
+		Metadata:    chunk.Metadata,
+	}
+	// Loaded chunks have no Node, so a row range tells table chunks apart too.
+	if _, ok := chunk.Node.(*Table); (ok || chunk.RowEnd > 0) && !chunk.IsParent {
+		rowStart, rowEnd := chunk.RowStart, chunk.RowEnd
+		record.RowStart = &rowStart
+		record.RowEnd = &rowEnd
+	}
+	if includeEmbedding {
+		record.Embedding = chunk.Embedding
+	}
+	return record
+}
+
+// Chunk converts the record back into a Chunk without a Node.
+func (c ChunkJSON) Chunk() *Chunk {
+	chunk := &Chunk{
+		ID:          c.ID,
+		ParentID:    c.ParentID,
+		IsParent:    c.IsParent,
+		Text:        c.Text,
+		ContextText: c.ContextText,
+		Metadata:    c.Metadata,
+		Embedding:   c.Embedding,
+	}
+	if c.RowStart != nil && c.RowEnd != nil {
+		chunk.RowStart, chunk.RowEnd = *c.RowStart, *c.RowEnd
+	}
+	return chunk
+}
+
+// WriteChunksJSONL writes one ChunkJSON object per line, ready for vector
+// database loaders.
+func (d *Document) WriteChunksJSONL(w io.Writer, opts JSONLOptions) error {
+	chunks := opts.Chunks
+	if chunks == nil {
+		chunks = d.ChunkRecords(opts.ChunkOptions)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, chunk := range chunks {
+		record := NewChunkJSON(chunk, opts.IncludeEmbeddings)
+		if opts.SourceFile != "" {
+			record.Metadata.SourceFile = opts.SourceFile
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package chipper
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const sampleBlocksJSON = `[
+	{"tag": "header", "level": 0, "page_idx": 0, "block_idx": 0, "bbox": [10, 10, 200, 20], "sentences": ["Results of Operations"]},
+	{"tag": "para", "level": 1, "page_idx": 0, "block_idx": 1, "bbox": [10, 30, 500, 60], "sentences": ["Revenue grew in the quarter.", "Costs were flat."]},
+	{"tag": "list_item", "level": 1, "page_idx": 0, "block_idx": 2, "bbox": [20, 70, 500, 80], "sentences": ["Mobility revenue increased."]},
+	{"tag": "table", "level": 1, "page_idx": 1, "block_idx": 3, "bbox": [10, 90, 500, 200], "name": "Revenue by offering (in millions)", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "2021"}, {"cell_value": "2022"}]},
+		{"type": "full_row", "cell_value": "Revenue", "col_span": 3},
+		{"type": "table_data_row", "cells": [{"cell_value": "Mobility"}, {"cell_value": "$ 1,536"}, {"cell_value": "$ 2,518"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Delivery"}, {"cell_value": "1,713"}, {"cell_value": "2,513"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Freight"}, {"cell_value": "(26)"}, {"cell_value": "—"}]}
+	]}
+]`
+
+func sampleDocument(t *testing.T) *Document {
+	t.Helper()
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(sampleBlocksJSON), &blocks); err != nil {
+		t.Fatalf("failed to decode sample blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+	doc.SourceFile = "sample.pdf"
+	return doc
+}
+
+func TestWriteChunksJSONL(t *testing.T) {
+	doc := sampleDocument(t)
+	chunks := doc.ChunkRecords(ChunkOptions{Mode: ChunkModeHierarchical, TableStrategy: TableChunkRows, MaxTokens: 32})
+	for i, chunk := range chunks {
+		chunk.Embedding = []float32{float32(i), 0.5}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.WriteChunksJSONL(&buf, JSONLOptions{Chunks: chunks, IncludeEmbeddings: true}); err != nil {
+		t.Fatalf("WriteChunksJSONL failed: %v", err)
+	}
+
+	golden := "testdata/chunks.golden.jsonl"
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("JSONL output mismatch:\nGot:\n%s\nWant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,209 @@
+package chipper
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type FindSectionsOptions struct {
+	// MinScore drops sections scoring below it, 0.5 by default.
+	MinScore float64
+	// Limit caps the number of results; zero returns every match.
+	Limit int
+}
+
+type SectionMatch struct {
+	Section *Section
+	// Score is the similarity of the normalized titles, 1 for equal titles.
+	Score float64
+	// Text is the section with its full subtree, as ToText(true, true).
+	Text string
+}
+
+// titleNumbering matches the numbering that opens lowercased filing titles,
+// such as "item 1a.", "part ii -", "note 3 –", "2.1" or "(a)", with the
+// separator following it. The marker must be followed by whitespace, a dash
+// or colon, or the end of the title, so "e.g. cash" keeps its words.
+// Submatches 1 and 3 hold roman numerals to check with romanNumeral and
+// submatch 2 a dotted number, which may be a quantity such as "1.5 billion".
+var titleNumbering = regexp.MustCompile(`^(?:(?:part|item|note|section|article|chapter|schedule|exhibit)\s+(?:[0-9]+[a-z]?|([ivxlc]+))\.?|([0-9]{1,2}(?:\.[0-9]{1,2})+)\.?|[0-9]{1,2}[.)]|\([a-z0-9]{1,3}\)|(?:([ivxlc]+)|[a-z])[.)])(?:\s*[-–—:]\s*|\s+|$)`)
+
+// romanNumeral matches the roman numerals up to 399, so that words such as
+// "civic" or "ill" are not taken for numbering.
+var romanNumeral = regexp.MustCompile(`^c{0,3}(?:xc|xl|l?x{0,3})(?:ix|iv|v?i{0,3})$`)
+
+// quantityWord matches the scale words that make a dotted number a quantity.
+var quantityWord = regexp.MustCompile(`^(?:thousand|million|billion|trillion|percent)\b`)
+
+// NormalizeSectionTitle lowercases title, strips leading numbering such as
+// "Item 1A." or "Note 3 –" and reduces punctuation to single spaces, so
+// "ITEM 2. MANAGEMENT’S DISCUSSION" becomes "managements discussion". A title
+// that is nothing but numbering keeps it.
+func NormalizeSectionTitle(title string) string {
+	numbering, rest := splitTitleNumbering(title)
+	if rest = cleanTitle(rest); rest != "" {
+		return rest
+	}
+	return cleanTitle(numbering)
+}
+
+// splitTitleNumbering separates the leading numbering of a lowercased title
+// from the rest of it.
+func splitTitleNumbering(title string) (numbering, rest string) {
+	rest = strings.ToLower(strings.Join(strings.Fields(title), " "))
+	for {
+		m := titleNumbering.FindStringSubmatchIndex(rest)
+		if m == nil {
+			return strings.TrimSpace(numbering), rest
+		}
+		for _, group := range []int{1, 3} {
+			if start := m[2*group]; start >= 0 && !romanNumeral.MatchString(rest[start:m[2*group+1]]) {
+				return strings.TrimSpace(numbering), rest
+			}
+		}
+		if m[4] >= 0 && quantityWord.MatchString(rest[m[1]:]) {
+			return strings.TrimSpace(numbering), rest
+		}
+		numbering += rest[:m[1]]
+		rest = rest[m[1]:]
+	}
+}
+
+func cleanTitle(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\'' || r == '’' || r == '‘':
+		case r == '&':
+			b.WriteString(" and ")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// FindSections returns the sections whose titles resemble query, best first
+// and in document order among equal scores. Titles and query are compared
+// after NormalizeSectionTitle, word by word with stemming and tolerance for
+// small spelling differences; a query that is only numbering, such as
+// "Item 1A", matches the sections numbered that way.
+func (d *Document) FindSections(query string, opts FindSectionsOptions) []SectionMatch {
+	minScore := opts.MinScore
+	if minScore == 0 {
+		minScore = 0.5
+	}
+	queryWords := titleWords(query)
+	if len(queryWords) == 0 {
+		return nil
+	}
+	// A query such as "Item 1A" names a section by its numbering alone.
+	queryNumbering, queryRest := splitTitleNumbering(query)
+	byNumber := cleanTitle(queryRest) == ""
+
+	var matches []SectionMatch
+	for node := range d.SectionsSeq() {
+		section := node.(*Section)
+		score := 0.0
+		if byNumber {
+			if numbering, _ := splitTitleNumbering(section.Title); cleanTitle(numbering) == cleanTitle(queryNumbering) {
+				score = 1
+			}
+		} else {
+			score = titleSimilarity(queryWords, titleWords(section.Title))
+		}
+		if score >= minScore {
+			matches = append(matches, SectionMatch{Section: section, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	for i := range matches {
+		matches[i].Text = matches[i].Section.ToText(true, true)
+	}
+	return matches
+}
+
+// titleWords returns the stemmed words of the normalized title without
+// stopwords, keeping them when the title has nothing else.
+func titleWords(title string) []string {
+	words := strings.Fields(NormalizeSectionTitle(title))
+	var kept []string
+	for _, word := range words {
+		if !EnglishStopwords[word] {
+			kept = append(kept, StemEnglish(word))
+		}
+	}
+	if len(kept) == 0 {
+		for _, word := range words {
+			kept = append(kept, StemEnglish(word))
+		}
+	}
+	return kept
+}
+
+// titleSimilarity averages how much of the query the title covers with the
+// Dice coefficient of both word lists, counting words that differ by a typo
+// in proportion to their similarity.
+func titleSimilarity(query, title []string) float64 {
+	if len(query) == 0 || len(title) == 0 {
+		return 0
+	}
+	matched := 0.0
+	for _, q := range query {
+		best := 0.0
+		for _, w := range title {
+			best = max(best, wordSimilarity(q, w))
+		}
+		matched += best
+	}
+	recall := matched / float64(len(query))
+	dice := 2 * matched / float64(len(query)+len(title))
+	return (recall + min(dice, 1)) / 2
+}
+
+// wordSimilarity is 1 for equal words, the edit distance ratio for words that
+// differ in at most one character in five, and 0 otherwise.
+func wordSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest < 4 {
+		return 0
+	}
+	ratio := 1 - float64(editDistance(ra, rb))/float64(longest)
+	if ratio < 0.8 {
+		return 0
+	}
+	return ratio
+}
+
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
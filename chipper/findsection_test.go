@@ -0,0 +1,83 @@
+package chipper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSectionTitle(t *testing.T) {
+	tests := map[string]string{
+		"ITEM 2. MANAGEMENT’S DISCUSSION AND ANALYSIS": "managements discussion and analysis",
+		"Item 1A. Risk Factors":                        "risk factors",
+		"PART I - FINANCIAL INFORMATION":               "financial information",
+		"Note 3 – Investments":                         "investments",
+		"2.1 Overview":                                 "overview",
+		"(a)  Exhibits":                                "exhibits",
+		"A. Summary":                                   "summary",
+		"2022 Highlights":                              "2022 highlights",
+		"Item 1A.":                                     "item 1a",
+		"civic. engagement":                            "civic engagement",
+		"ill) x":                                       "ill x",
+		"1.5 billion notes":                            "1 5 billion notes",
+		"e.g. cash":                                    "e g cash",
+		"IV. Liquidity":                                "liquidity",
+		"Part XIV: Exhibits":                           "exhibits",
+	}
+	for title, want := range tests {
+		if got := NormalizeSectionTitle(title); got != want {
+			t.Errorf("NormalizeSectionTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestFindSections(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		title string
+	}{
+		{"Item 2. Management's Discussion and Analysis", "ITEM 2. MANAGEMENT’S DISCUSSION AND ANALYSIS OF FINANCIAL CONDITION AND RESULTS OF OPERATIONS"},
+		{"risk factors", "ITEM 1A. RISK FACTORS"},
+		{"  LIQUIDITY and capital resorces ", "Liquidity and Capital Resources"},
+		{"Item 1A", "ITEM 1A. RISK FACTORS"},
+	}
+	for _, tt := range tests {
+		matches := doc.FindSections(tt.query, FindSectionsOptions{Limit: 3})
+		if len(matches) == 0 {
+			t.Errorf("%q: no sections found", tt.query)
+			continue
+		}
+		best := matches[0]
+		if best.Section.Title != tt.title {
+			t.Errorf("%q: best match %q, want %q", tt.query, best.Section.Title, tt.title)
+		}
+		for i := 1; i < len(matches); i++ {
+			if matches[i].Score > matches[i-1].Score {
+				t.Errorf("%q: matches are not ranked by score", tt.query)
+			}
+		}
+		if !strings.HasPrefix(best.Text, best.Section.Title) || len(best.Text) <= len(best.Section.Title) {
+			t.Errorf("%q: expected the section text with its subtree, got %d bytes", tt.query, len(best.Text))
+		}
+	}
+
+	matches := doc.FindSections("risk factors", FindSectionsOptions{})
+	if len(matches) < 3 || matches[0].Score != 1 || matches[1].Score >= 1 {
+		t.Fatalf("expected the exact title first and partial titles below it, got %d matches", len(matches))
+	}
+	for _, match := range matches {
+		if match.Score < 0.5 {
+			t.Fatalf("match %q scores %.2f below the default minimum", match.Section.Title, match.Score)
+		}
+	}
+	if matches := doc.FindSections("risk factors", FindSectionsOptions{MinScore: 0.95}); len(matches) != 1 {
+		t.Fatalf("expected a single match at MinScore 0.95, got %d", len(matches))
+	}
+	if matches := doc.FindSections("quantum entanglement", FindSectionsOptions{}); len(matches) != 0 {
+		t.Fatalf("expected no matches for an unrelated query, got %q", matches[0].Section.Title)
+	}
+}
@@ -0,0 +1,232 @@
+package chipper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PageRange is an inclusive range of zero-based page indices.
+type PageRange struct {
+	First int
+	Last  int
+}
+
+// SearchFilter restricts search results by chunk metadata. Zero-valued fields
+// do not filter.
+type SearchFilter struct {
+	Pages *PageRange
+	// SectionPrefix matches chunks whose section path starts with these
+	// titles, compared case-insensitively.
+	SectionPrefix []string
+	Tags          []string
+	SourceFile    string
+}
+
+func (f *SearchFilter) Match(chunk *Chunk) bool {
+	if f == nil {
+		return true
+	}
+	meta := chunk.Metadata
+	if f.Pages != nil {
+		inRange := false
+		for _, page := range meta.Pages {
+			if page >= f.Pages.First && page <= f.Pages.Last {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+	if len(f.SectionPrefix) > len(meta.SectionPath) {
+		return false
+	}
+	for i, title := range f.SectionPrefix {
+		if !strings.EqualFold(strings.TrimSpace(title), strings.TrimSpace(meta.SectionPath[i])) {
+			return false
+		}
+	}
+	if len(f.Tags) > 0 {
+		tagged := false
+		for _, tag := range f.Tags {
+			if tag == meta.Tag {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+	if f.SourceFile != "" && f.SourceFile != meta.SourceFile {
+		return false
+	}
+	return true
+}
+
+type SearchResult struct {
+	Chunk *Chunk
+	Score float64
+	// Highlights holds the matching sentences of lexical search results.
+	Highlights []string
+}
+
+// VectorIndex is an in-memory cosine similarity index over embedded chunks,
+// which may come from any number of documents told apart by their source
+// file.
+type VectorIndex struct {
+	mu     sync.RWMutex
+	chunks []*Chunk
+	norms  []float64
+	dim    int
+	keys   map[string]bool
+}
+
+func NewVectorIndex() *VectorIndex {
+	return &VectorIndex{}
+}
+
+func (ix *VectorIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.chunks)
+}
+
+// Add indexes chunks that already carry an embedding, see EmbedChunks. A
+// chunk without an embedding, of another dimension than the index, or with
+// the source file and ID of one already indexed is an error, and no chunk is
+// added then.
+func (ix *VectorIndex) Add(chunks ...*Chunk) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.keys == nil {
+		ix.keys = make(map[string]bool)
+	}
+	dim := ix.dim
+	batch := make(map[string]bool, len(chunks))
+	for _, chunk := range chunks {
+		key := chunkIndexKey(chunk)
+		if ix.keys[key] || batch[key] {
+			return fmt.Errorf("chunk %s of %q is already indexed", chunk.ID, chunk.Metadata.SourceFile)
+		}
+		batch[key] = true
+		if len(chunk.Embedding) == 0 {
+			return fmt.Errorf("chunk %s has no embedding", chunk.ID)
+		}
+		if dim == 0 {
+			dim = len(chunk.Embedding)
+		} else if len(chunk.Embedding) != dim {
+			return fmt.Errorf("chunk %s has %d dimensions, index has %d", chunk.ID, len(chunk.Embedding), dim)
+		}
+	}
+
+	ix.dim = dim
+	for _, chunk := range chunks {
+		ix.chunks = append(ix.chunks, chunk)
+		ix.norms = append(ix.norms, vectorNorm(chunk.Embedding))
+		ix.keys[chunkIndexKey(chunk)] = true
+	}
+	return nil
+}
+
+// Search returns the k chunks most similar to query by cosine similarity,
+// best first, considering only chunks matched by filter.
+func (ix *VectorIndex) Search(query []float32, k int, filter *SearchFilter) ([]SearchResult, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if len(ix.chunks) > 0 && len(query) != ix.dim {
+		return nil, fmt.Errorf("query has %d dimensions, index has %d", len(query), ix.dim)
+	}
+
+	queryNorm := vectorNorm(query)
+	var results []SearchResult
+	for i, chunk := range ix.chunks {
+		if !filter.Match(chunk) {
+			continue
+		}
+		score := 0.0
+		if queryNorm > 0 && ix.norms[i] > 0 {
+			score = dot(query, chunk.Embedding) / (queryNorm * ix.norms[i])
+		}
+		results = append(results, SearchResult{Chunk: chunk, Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// SearchText embeds query with embedder and searches the index with it.
+func (ix *VectorIndex) SearchText(ctx context.Context, embedder Embedder, query string, k int, filter *SearchFilter) ([]SearchResult, error) {
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 query", len(vectors))
+	}
+	return ix.Search(vectors[0], k, filter)
+}
+
+// Save writes the indexed chunks with their embeddings to path as JSON.
+// Chunk nodes are not saved, so loaded chunks have a nil Node.
+func (ix *VectorIndex) Save(path string) error {
+	ix.mu.RLock()
+	records := make([]ChunkJSON, len(ix.chunks))
+	for i, chunk := range ix.chunks {
+		records[i] = NewChunkJSON(chunk, true)
+	}
+	ix.mu.RUnlock()
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func LoadVectorIndex(path string) (*VectorIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []ChunkJSON
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	ix := NewVectorIndex()
+	for _, record := range records {
+		if err := ix.Add(record.Chunk()); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// chunkIndexKey identifies chunk among those of every indexed document.
+func chunkIndexKey(chunk *Chunk) string {
+	return chunk.Metadata.SourceFile + "\x00" + chunk.ID
+}
+
+func vectorNorm(v []float32) float64 {
+	return math.Sqrt(dot(v, v))
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
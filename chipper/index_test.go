@@ -0,0 +1,144 @@
+package chipper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVectorIndex(t *testing.T) {
+	doc := sampleDocument(t)
+	embedder := NewHashEmbedder(64)
+	chunks, err := doc.EmbedChunks(context.Background(), embedder, EmbedOptions{})
+	if err != nil {
+		t.Fatalf("EmbedChunks failed: %v", err)
+	}
+
+	ix := NewVectorIndex()
+	if err := ix.Add(chunks...); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	results, err := ix.SearchText(context.Background(), embedder, "Mobility revenue increased", 2, nil)
+	if err != nil {
+		t.Fatalf("SearchText failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Chunk.ID != "chunk-2" {
+		t.Fatalf("expected chunk-2 as the best match, got %+v", results)
+	}
+	if results[0].Score < results[1].Score {
+		t.Fatal("results are not sorted by score")
+	}
+
+	t.Run("Filters", func(t *testing.T) {
+		filters := map[string]*SearchFilter{
+			"page":    {Pages: &PageRange{First: 1, Last: 1}},
+			"tag":     {Tags: []string{"table"}},
+			"section": {SectionPrefix: []string{"results of operations"}, Tags: []string{"table"}},
+		}
+		for name, filter := range filters {
+			results, err := ix.SearchText(context.Background(), embedder, "Mobility revenue", 0, filter)
+			if err != nil {
+				t.Fatalf("%s: SearchText failed: %v", name, err)
+			}
+			if len(results) != 1 || results[0].Chunk.ID != "chunk-3" {
+				t.Fatalf("%s: expected only the table chunk, got %d results", name, len(results))
+			}
+		}
+
+		results, err := ix.Search(chunks[0].Embedding, 0, &SearchFilter{SectionPrefix: []string{"Liquidity"}})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("expected no results outside the section, got %d", len(results))
+		}
+	})
+
+	t.Run("SaveLoad", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "index.json")
+		if err := ix.Save(path); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		loaded, err := LoadVectorIndex(path)
+		if err != nil {
+			t.Fatalf("LoadVectorIndex failed: %v", err)
+		}
+		if loaded.Len() != ix.Len() {
+			t.Fatalf("expected %d chunks after load, got %d", ix.Len(), loaded.Len())
+		}
+		reloaded, err := loaded.SearchText(context.Background(), embedder, "Mobility revenue increased", 2, nil)
+		if err != nil {
+			t.Fatalf("SearchText failed: %v", err)
+		}
+		for i := range results {
+			if reloaded[i].Chunk.ID != results[i].Chunk.ID || reloaded[i].Chunk.Metadata.SourceFile != "sample.pdf" {
+				t.Fatalf("loaded index returned %s, expected %s", reloaded[i].Chunk.ID, results[i].Chunk.ID)
+			}
+		}
+	})
+
+	if err := ix.Add(&Chunk{ID: "bad", Embedding: []float32{1}}); err == nil {
+		t.Fatal("expected a dimension mismatch error")
+	}
+	if err := ix.Add(chunks[0]); err == nil {
+		t.Fatal("expected an error for a chunk already indexed")
+	}
+
+	// The same IDs from another document do not collide.
+	other := sampleDocument(t)
+	other.SourceFile = "other.pdf"
+	otherChunks, err := other.EmbedChunks(context.Background(), embedder, EmbedOptions{})
+	if err != nil {
+		t.Fatalf("EmbedChunks failed: %v", err)
+	}
+	if err := ix.Add(otherChunks...); err != nil {
+		t.Fatalf("Add of another document failed: %v", err)
+	}
+}
+
+func TestVectorIndexRowRanges(t *testing.T) {
+	doc := sampleDocument(t)
+	chunks := doc.ChunkRecords(ChunkOptions{TableStrategy: TableChunkRows, MaxTokens: 32})
+	if err := EmbedChunks(context.Background(), NewHashEmbedder(16), chunks, EmbedOptions{}); err != nil {
+		t.Fatalf("EmbedChunks failed: %v", err)
+	}
+	ix := NewVectorIndex()
+	if err := ix.Add(chunks...); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Loaded chunks have no Node, and saving them again must keep their rows.
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.json"), filepath.Join(dir, "second.json")
+	if err := ix.Save(first); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadVectorIndex(first)
+	if err != nil {
+		t.Fatalf("LoadVectorIndex failed: %v", err)
+	}
+	if err := loaded.Save(second); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	firstData, _ := os.ReadFile(first)
+	secondData, _ := os.ReadFile(second)
+	if !bytes.Equal(firstData, secondData) || !bytes.Contains(secondData, []byte(`"row_start":2,"row_end":4`)) {
+		t.Fatalf("row ranges lost on reload:\n%s\n%s", firstData, secondData)
+	}
+
+	// A failing batch adds nothing.
+	fresh := NewVectorIndex()
+	bad := &Chunk{ID: "bad", Embedding: []float32{1}}
+	if err := fresh.Add(chunks[0], bad); err == nil {
+		t.Fatal("expected a dimension mismatch error")
+	}
+	if fresh.Len() != 0 {
+		t.Fatalf("failed Add left %d chunks in the index", fresh.Len())
+	}
+	if err := fresh.Add(bad); err != nil {
+		t.Fatalf("the index kept the dimension of a failed batch: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package chipper
+
+import "iter"
+
+// Descendants yields the descendants of node, excluding node itself, depth
+// first in document order. It is Walk in the form of an iterator, so that the
+// two never disagree on the order of the tree.
+func Descendants(node BlockInterface) iter.Seq[BlockInterface] {
+	return func(yield func(BlockInterface) bool) {
+		Walk(node, WalkFunc(func(child BlockInterface, ctx WalkContext) WalkAction {
+			if ctx.Depth > 0 && !yield(child) {
+				return WalkAbort
+			}
+			return WalkContinue
+		}))
+	}
+}
+
+// Ancestors yields the parents of node from the closest one up to the root,
+// the reverse order of ParentChain.
+func Ancestors(node BlockInterface) iter.Seq[BlockInterface] {
+	return func(yield func(BlockInterface) bool) {
+		for parent := node.ParentNode(); parent != nil; parent = parent.ParentNode() {
+			if !yield(parent) {
+				return
+			}
+		}
+	}
+}
+
+// filterSeq yields the nodes of seq for which match returns true.
+func filterSeq(seq iter.Seq[BlockInterface], match func(BlockInterface) bool) iter.Seq[BlockInterface] {
+	return func(yield func(BlockInterface) bool) {
+		for node := range seq {
+			if match(node) && !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+func isTable(node BlockInterface) bool {
+	_, ok := node.(*Table)
+	return ok
+}
+
+func isSection(node BlockInterface) bool {
+	_, ok := node.(*Section)
+	return ok
+}
+
+func isParagraph(node BlockInterface) bool {
+	_, ok := node.(*Paragraph)
+	return ok
+}
+
+// All yields every node of the document in document order.
+func (d *Document) All() iter.Seq[BlockInterface] {
+	return Descendants(d.rootNode)
+}
+
+// Blocks yields the Block embedded in every node of the document, for
+// reading the fields common to all node types.
+func (d *Document) Blocks() iter.Seq[*Block] {
+	return func(yield func(*Block) bool) {
+		for node := range d.All() {
+			if block := blockOf(node); block != nil && !yield(block) {
+				return
+			}
+		}
+	}
+}
+
+// ChunksSeq yields the nodes of Chunks without collecting them first.
+func (d *Document) ChunksSeq() iter.Seq[BlockInterface] {
+	return filterSeq(d.All(), isLeafChunk)
+}
+
+// SectionsSeq yields the nodes of Sections without collecting them first.
+func (d *Document) SectionsSeq() iter.Seq[BlockInterface] {
+	return filterSeq(d.All(), isSection)
+}
+
+// TablesSeq yields the nodes of Tables without collecting them first.
+func (d *Document) TablesSeq() iter.Seq[BlockInterface] {
+	return filterSeq(d.All(), isTable)
+}
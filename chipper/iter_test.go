@@ -0,0 +1,75 @@
+package chipper
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDocumentIterators(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+
+	if got := slices.Collect(doc.ChunksSeq()); !slices.Equal(got, doc.Chunks()) {
+		t.Errorf("ChunksSeq yielded %d nodes, Chunks returned %d", len(got), len(doc.Chunks()))
+	}
+	if got := slices.Collect(doc.SectionsSeq()); !slices.Equal(got, doc.Sections()) {
+		t.Errorf("SectionsSeq yielded %d nodes, Sections returned %d", len(got), len(doc.Sections()))
+	}
+	if got := slices.Collect(doc.TablesSeq()); !slices.Equal(got, doc.Tables()) {
+		t.Errorf("TablesSeq yielded %d nodes, Tables returned %d", len(got), len(doc.Tables()))
+	}
+
+	all := slices.Collect(doc.All())
+	blocks := slices.Collect(doc.Blocks())
+	if len(all) != len(blocks) {
+		t.Fatalf("All yielded %d nodes but Blocks %d", len(all), len(blocks))
+	}
+	for i, node := range all {
+		if blockOf(node) != blocks[i] {
+			t.Fatalf("Blocks[%d] is not the block of All[%d]", i, i)
+		}
+	}
+
+	seen := 0
+	for table := range doc.TablesSeq() {
+		if _, ok := table.(*Table); !ok {
+			t.Fatalf("TablesSeq yielded %T", table)
+		}
+		seen++
+		if seen == 3 {
+			break
+		}
+	}
+	if seen != 3 {
+		t.Fatalf("expected to stop after 3 tables, saw %d", seen)
+	}
+}
+
+func TestDescendantsAndAncestors(t *testing.T) {
+	doc := sampleDocument(t)
+	section := doc.Sections()[0]
+
+	var tags []string
+	for node := range Descendants(section) {
+		tags = append(tags, blockOf(node).Tag)
+	}
+	if !slices.Equal(tags, []string{"para", "list_item", "table"}) {
+		t.Fatalf("unexpected descendants %v", tags)
+	}
+
+	table := doc.Tables()[0]
+	ancestors := slices.Collect(Ancestors(table))
+	chain := blockOf(table).ParentChain()
+	slices.Reverse(chain)
+	if len(ancestors) != 2 || !slices.Equal(ancestors, chain) {
+		t.Fatalf("Ancestors yielded %d nodes, expected the reversed ParentChain of %d", len(ancestors), len(chain))
+	}
+	if blockOf(ancestors[0]) != blockOf(section) {
+		t.Fatalf("closest ancestor of the table should be its section")
+	}
+	for range Ancestors(table) {
+		break
+	}
+}
@@ -0,0 +1,163 @@
+package chipper
+
+import "slices"
+
+// TableFragment is one of the table blocks a merged table was built from.
+type TableFragment struct {
+	BlockIdx int
+	PageIdx  int
+	Bbox     []float64
+	// RowStart and RowEnd delimit the rows of the merged table that came
+	// from this fragment.
+	RowStart int
+	RowEnd   int
+}
+
+// Pages returns the pages the table spans, in order.
+func (t *Table) Pages() []int {
+	if len(t.Fragments) == 0 {
+		return []int{t.PageIdx}
+	}
+	var pages []int
+	for _, fragment := range t.Fragments {
+		if !slices.Contains(pages, fragment.PageIdx) {
+			pages = append(pages, fragment.PageIdx)
+		}
+	}
+	return pages
+}
+
+// rowPages returns the pages holding rows [start, end) of a merged table.
+func (t *Table) rowPages(start, end int) []int {
+	pages := []int{}
+	for _, fragment := range t.Fragments {
+		overlaps := fragment.RowStart < end && start < fragment.RowEnd
+		if (overlaps || start == end && fragment.RowStart == start) && !slices.Contains(pages, fragment.PageIdx) {
+			pages = append(pages, fragment.PageIdx)
+		}
+	}
+	return pages
+}
+
+func (t *Table) lastPage() int {
+	pages := t.Pages()
+	return pages[len(pages)-1]
+}
+
+// continues reports whether next looks like the continuation of t on the
+// following page: same width, lined up with the last fragment of t when both
+// have a bounding box, and either no headers or the same headers.
+func (t *Table) continues(next *Table) bool {
+	if next.PageIdx != t.lastPage()+1 || next.NumCols() != t.NumCols() {
+		return false
+	}
+	if !alignedBboxes(t.lastBbox(), next.Bbox) {
+		return false
+	}
+	if len(next.Headers) == 0 {
+		return true
+	}
+	return len(t.Headers) > 0 && slices.Equal(normalizedLabels(t.ColumnLabels()), normalizedLabels(next.ColumnLabels()))
+}
+
+func (t *Table) lastBbox() []float64 {
+	if len(t.Fragments) == 0 {
+		return t.Bbox
+	}
+	return t.Fragments[len(t.Fragments)-1].Bbox
+}
+
+// alignedBboxes reports whether two boxes span mostly the same columns of the
+// page, overlapping horizontally by at least 80% of the wider one. Missing
+// boxes do not rule out a match.
+func alignedBboxes(a, b []float64) bool {
+	if len(a) != 4 || len(b) != 4 {
+		return true
+	}
+	overlap := min(a[2], b[2]) - max(a[0], b[0])
+	return overlap >= 0.8*max(a[2]-a[0], b[2]-b[0])
+}
+
+func normalizedLabels(labels []string) []string {
+	normalized := make([]string, len(labels))
+	for i, label := range labels {
+		normalized[i] = normalizeLabel(label)
+	}
+	return normalized
+}
+
+func (t *Table) appendFragment(next *Table) {
+	if len(t.Fragments) == 0 {
+		t.Fragments = append(t.Fragments, TableFragment{
+			BlockIdx: t.BlockIdx,
+			PageIdx:  t.PageIdx,
+			Bbox:     t.Bbox,
+			RowEnd:   len(t.Rows),
+		})
+	}
+	t.Fragments = append(t.Fragments, TableFragment{
+		BlockIdx: next.BlockIdx,
+		PageIdx:  next.PageIdx,
+		Bbox:     next.Bbox,
+		RowStart: len(t.Rows),
+		RowEnd:   len(t.Rows) + len(next.Rows),
+	})
+	t.Rows = append(t.Rows, next.Rows...)
+	t.adoptCellNodes()
+	for _, child := range next.Children {
+		t.AddChild(child)
+	}
+	// Read links footnotes to the piece they follow, which is the last one.
+	t.Footnotes = append(t.Footnotes, next.Footnotes...)
+	if t.UnitNote == "" {
+		t.UnitNote = next.UnitNote
+	}
+}
+
+// MergeSplitTables joins tables that the parser split at a page break: a
+// table directly followed, with no content in between, by a table on the
+// next page with the same number of columns and either no headers or the
+// same headers. When the parser gave bounding boxes, the continuation must
+// also line up with the table and open its page, with no block above it.
+// Repeated headers are dropped, the continuation is removed from the tree
+// and the pieces are recorded in Table.Fragments. It returns the number of
+// tables merged away.
+func (d *Document) MergeSplitTables() int {
+	pageTops := make(map[int]float64)
+	for block := range d.Blocks() {
+		if len(block.Bbox) != 4 {
+			continue
+		}
+		if top, ok := pageTops[block.PageIdx]; !ok || block.Bbox[1] < top {
+			pageTops[block.PageIdx] = block.Bbox[1]
+		}
+	}
+	opensPage := func(t *Table) bool {
+		return len(t.Bbox) != 4 || t.Bbox[1] <= pageTops[t.PageIdx]
+	}
+
+	merged := 0
+	mergeChildren := func(node BlockInterface) {
+		block := blockOf(node)
+		children := block.Children[:0]
+		for _, child := range block.Children {
+			if table, ok := child.(*Table); ok && len(children) > 0 {
+				if prev, ok := children[len(children)-1].(*Table); ok && prev.continues(table) && opensPage(table) {
+					prev.appendFragment(table)
+					merged++
+					continue
+				}
+			}
+			children = append(children, child)
+		}
+		clear(block.Children[len(children):])
+		block.Children = children
+		block.reindexChildren()
+	}
+
+	d.Walk(WalkFunc(func(node BlockInterface, _ WalkContext) WalkAction {
+		mergeChildren(node)
+		return WalkContinue
+	}))
+	return merged
+}
@@ -0,0 +1,145 @@
+package chipper
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const splitTableBlocksJSON = `[
+	{"tag": "header", "level": 0, "page_idx": 3, "block_idx": 0, "sentences": ["Balance Sheets"]},
+	{"tag": "table", "level": 1, "page_idx": 3, "block_idx": 1, "name": "Assets", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "2021"}, {"cell_value": "2022"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Cash"}, {"cell_value": "1"}, {"cell_value": "2"}]}
+	]},
+	{"tag": "table", "level": 1, "page_idx": 4, "block_idx": 2, "name": "", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "2021"}, {"cell_value": "2022"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Goodwill"}, {"cell_value": "3"}, {"cell_value": "4"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Total"}, {"cell_value": "4"}, {"cell_value": "6"}]}
+	]},
+	{"tag": "table", "level": 1, "page_idx": 5, "block_idx": 3, "name": "", "table_rows": [
+		{"type": "table_data_row", "cells": [{"cell_value": "Equity"}, {"cell_value": "5"}, {"cell_value": "6"}]}
+	]},
+	{"tag": "para", "level": 1, "page_idx": 5, "block_idx": 4, "sentences": ["See notes."]},
+	{"tag": "table", "level": 1, "page_idx": 6, "block_idx": 5, "name": "", "table_rows": [
+		{"type": "table_data_row", "cells": [{"cell_value": "Debt"}, {"cell_value": "7"}, {"cell_value": "8"}]}
+	]},
+	{"tag": "table", "level": 1, "page_idx": 7, "block_idx": 6, "name": "", "table_rows": [
+		{"type": "table_data_row", "cells": [{"cell_value": "Notes"}, {"cell_value": "9"}]}
+	]}
+]`
+
+func TestMergeSplitTables(t *testing.T) {
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(splitTableBlocksJSON), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+
+	if merged := doc.MergeSplitTables(); merged != 2 {
+		t.Fatalf("expected 2 tables merged away, got %d", merged)
+	}
+	// Removing the continuations must not leave stale sibling links.
+	checkNavigation(t, doc)
+	tables := doc.Tables()
+	if len(tables) != 3 {
+		t.Fatalf("expected 3 tables after merging, got %d", len(tables))
+	}
+
+	table := tables[0].(*Table)
+	if len(table.Rows) != 4 || len(table.Headers) != 1 {
+		t.Fatalf("expected 4 rows under 1 header, got %d rows and %d headers", len(table.Rows), len(table.Headers))
+	}
+	if pages := table.Pages(); len(pages) != 3 || pages[0] != 3 || pages[2] != 5 {
+		t.Fatalf("unexpected merged pages: %v", pages)
+	}
+	if len(table.Fragments) != 3 || table.Fragments[1].BlockIdx != 2 || table.Fragments[1].RowStart != 1 || table.Fragments[1].RowEnd != 3 {
+		t.Fatalf("unexpected fragments: %+v", table.Fragments)
+	}
+	if value, ok := table.Lookup("Total", "2022"); !ok || value.Number != 6 {
+		t.Fatalf("merged rows are not addressable: %+v", value)
+	}
+
+	chunks := doc.ChunkRecords(ChunkOptions{TableStrategy: TableChunkRows, MaxTokens: 15})
+	if chunks[0].Metadata.Pages[0] != 3 || chunks[len(chunks)-1].Node != tables[2] {
+		t.Fatalf("unexpected chunk metadata after merging: %+v", chunks[0].Metadata)
+	}
+	for _, chunk := range chunks {
+		if chunk.Node == table && chunk.RowStart >= 3 && (len(chunk.Metadata.Pages) != 1 || chunk.Metadata.Pages[0] != 5) {
+			t.Fatalf("row piece %s should be on page 5, got %v", chunk.ID, chunk.Metadata.Pages)
+		}
+	}
+
+	if merged := doc.MergeSplitTables(); merged != 0 {
+		t.Fatalf("expected merging to be idempotent, merged %d more", merged)
+	}
+
+	t.Run("Footnotes", func(t *testing.T) {
+		var blocks []interface{}
+		if err := json.Unmarshal([]byte(`[
+			{"tag": "table", "level": 1, "page_idx": 3, "block_idx": 0, "name": "", "table_rows": [
+				{"type": "table_data_row", "cells": [{"cell_value": "Rides"}, {"cell_value": "1"}]}
+			]},
+			{"tag": "table", "level": 1, "page_idx": 4, "block_idx": 1, "name": "(In millions)", "table_rows": [
+				{"type": "table_data_row", "cells": [{"cell_value": "Eats"}, {"cell_value": "2"}]}
+			]},
+			{"tag": "list_item", "level": 1, "page_idx": 4, "block_idx": 2, "sentences": ["* Percentage not meaningful."]}
+		]`), &blocks); err != nil {
+			t.Fatalf("failed to decode blocks: %v", err)
+		}
+		doc := NewDocument(blocks)
+		if merged := doc.MergeSplitTables(); merged != 1 {
+			t.Fatalf("expected 1 table merged away, got %d", merged)
+		}
+
+		// The footnotes follow the last piece and must move to the merged table.
+		table := doc.Tables()[0].(*Table)
+		if table.FootnoteText() != "* Percentage not meaningful." || table.UnitNote != "(In millions)" {
+			t.Fatalf("merged table lost footnotes %q or unit note %q", table.FootnoteText(), table.UnitNote)
+		}
+		chunks := doc.ChunkRecords(ChunkOptions{TableStrategy: TableChunkRows})
+		if !strings.HasSuffix(chunks[0].Text, "* Percentage not meaningful.") {
+			t.Fatalf("table chunk lost the footnote:\n%s", chunks[0].Text)
+		}
+	})
+}
+
+func TestMergeSplitTablesLayout(t *testing.T) {
+	// Two data-only tables with the same width on consecutive pages, the
+	// second placed at bbox and optionally below a paragraph in another column.
+	build := func(bbox string, paraAbove bool) *Document {
+		src := `[
+			{"tag": "header", "level": 0, "page_idx": 3, "block_idx": 0, "bbox": [50, 50, 560, 62], "sentences": ["Debt"]},
+			{"tag": "table", "level": 1, "page_idx": 3, "block_idx": 1, "bbox": [50, 500, 560, 740], "table_rows": [
+				{"type": "table_data_row", "cells": [{"cell_value": "Notes"}, {"cell_value": "1"}]}
+			]},
+			{"tag": "table", "level": 1, "page_idx": 4, "block_idx": 2, "bbox": ` + bbox + `, "table_rows": [
+				{"type": "table_data_row", "cells": [{"cell_value": "Leases"}, {"cell_value": "2"}]}
+			]}`
+		if paraAbove {
+			src += `,
+			{"tag": "para", "level": 0, "page_idx": 4, "block_idx": 3, "bbox": [320, 50, 560, 90], "sentences": ["Sidebar."]}`
+		}
+		var blocks []interface{}
+		if err := json.Unmarshal([]byte(src+"]"), &blocks); err != nil {
+			t.Fatalf("failed to decode blocks: %v", err)
+		}
+		return NewDocument(blocks)
+	}
+
+	cases := []struct {
+		name      string
+		bbox      string
+		paraAbove bool
+		merged    int
+	}{
+		{"aligned at the top", "[52, 50, 558, 200]", false, 1},
+		{"other columns", "[50, 50, 300, 200]", false, 0},
+		{"below other content", "[50, 300, 560, 400]", true, 0},
+	}
+	for _, c := range cases {
+		if merged := build(c.bbox, c.paraAbove).MergeSplitTables(); merged != c.merged {
+			t.Errorf("%s: merged %d tables, want %d", c.name, merged, c.merged)
+		}
+	}
+}
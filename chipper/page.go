@@ -0,0 +1,220 @@
+package chipper
+
+import (
+	"sort"
+	"strings"
+)
+
+// Page lists the blocks of one page of the document in reading order.
+type Page struct {
+	// Index is the zero-based page index, as Block.PageIdx.
+	Index int
+	// Width and Height are the page size in PDF points, zero when the
+	// document does not know it.
+	Width  float64
+	Height float64
+	Blocks []PageBlock
+}
+
+// PageBlock is a node of the document as it appears on a page. A table split
+// across pages appears on each of them with the rows found there, and a table
+// cell carrying a page other than that of its row appears on its own page.
+type PageBlock struct {
+	Node BlockInterface
+	// Page is the index of the page the block is placed on.
+	Page int
+	// Cell is set, with Node being its table, for a cell placed on a page
+	// of its own.
+	Cell *TableCell
+	// RowStart and RowEnd delimit the table rows on the page.
+	RowStart int
+	RowEnd   int
+	// Bbox is the position of the block on the page, nil when unknown.
+	Bbox []float64
+}
+
+// Pages returns one Page per page of the document, so that Pages()[i].Index
+// is i, with every block placed on the page it was found on and sorted top to
+// bottom, then left to right. Blocks without a position follow the others in
+// document order.
+func (d *Document) Pages() []*Page {
+	var placed []PageBlock
+	d.placeBlocks(func(block PageBlock) {
+		placed = append(placed, block)
+	})
+
+	numPages := d.NumPages
+	for _, block := range placed {
+		numPages = max(numPages, block.Page+1)
+	}
+	pages := make([]*Page, numPages)
+	for i := range pages {
+		pages[i] = &Page{Index: i, Width: d.PageWidth, Height: d.PageHeight}
+	}
+	for _, block := range placed {
+		page := pages[block.Page]
+		page.Blocks = append(page.Blocks, block)
+	}
+	for _, page := range pages {
+		sortReadingOrder(page.Blocks)
+	}
+	return pages
+}
+
+// pageBlocks returns the blocks Pages places on page, without building the
+// other pages.
+func (d *Document) pageBlocks(page int) []PageBlock {
+	var blocks []PageBlock
+	d.placeBlocks(func(block PageBlock) {
+		if block.Page == page {
+			blocks = append(blocks, block)
+		}
+	})
+	sortReadingOrder(blocks)
+	return blocks
+}
+
+// placeBlocks calls add with every block of the document in document order,
+// with Page set to the page it is placed on: tables once per fragment and
+// cells found on another page than their row once more. Blocks without a
+// page are left out.
+func (d *Document) placeBlocks(add func(PageBlock)) {
+	place := func(page int, block PageBlock) {
+		if page >= 0 {
+			block.Page = page
+			add(block)
+		}
+	}
+	for node := range d.All() {
+		table, ok := node.(*Table)
+		if !ok {
+			place(node.NodePage(), PageBlock{Node: node, Bbox: node.NodeBbox()})
+			continue
+		}
+		fragments := table.Fragments
+		if len(fragments) == 0 {
+			fragments = []TableFragment{{PageIdx: table.PageIdx, Bbox: table.Bbox, RowEnd: len(table.Rows)}}
+		}
+		for _, fragment := range fragments {
+			place(fragment.PageIdx, PageBlock{Node: table, RowStart: fragment.RowStart, RowEnd: fragment.RowEnd, Bbox: fragment.Bbox})
+			for _, row := range table.Rows[fragment.RowStart:fragment.RowEnd] {
+				for _, cell := range row.Cells {
+					if cell.PageIdx >= 0 && cell.PageIdx != fragment.PageIdx {
+						place(cell.PageIdx, PageBlock{Node: table, Cell: cell, Bbox: cell.Bbox})
+					}
+				}
+			}
+		}
+	}
+}
+
+func sortReadingOrder(blocks []PageBlock) {
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return readsBefore(blocks[i], blocks[j])
+	})
+}
+
+// position returns the top and left of the block, and whether it is known.
+func (pb PageBlock) position() (float64, float64, bool) {
+	if len(pb.Bbox) == 4 {
+		return pb.Bbox[1], pb.Bbox[0], true
+	}
+	if pb.Cell == nil {
+		if block := blockOf(pb.Node); block != nil && block.Top >= 0 && block.Left >= 0 {
+			return block.Top, block.Left, true
+		}
+	}
+	return 0, 0, false
+}
+
+func readsBefore(a, b PageBlock) bool {
+	aTop, aLeft, aKnown := a.position()
+	bTop, bLeft, bKnown := b.position()
+	if !aKnown || !bKnown {
+		return aKnown && !bKnown
+	}
+	if aTop != bTop {
+		return aTop < bTop
+	}
+	return aLeft < bLeft
+}
+
+// Text returns the text of the block as found on the page: a section title,
+// the sentences of a paragraph or list item, the table headers with the rows
+// on the page, or the text of a cell.
+func (pb PageBlock) Text() string {
+	if pb.Cell != nil {
+		return pb.Cell.ToText()
+	}
+	switch node := pb.Node.(type) {
+	case *Section:
+		return node.Title
+	case *Table:
+		text := ""
+		for _, header := range node.Headers {
+			text += header.ToText(false, false) + "\n"
+		}
+		for _, row := range node.Rows[pb.RowStart:pb.RowEnd] {
+			text += row.ToText(false, false) + "\n"
+		}
+		return strings.TrimSpace(text)
+	}
+	return pb.Node.ToText(false, false)
+}
+
+// Markdown renders the block as in Page.ToMarkdown.
+func (pb PageBlock) Markdown() string {
+	if pb.Cell != nil {
+		return pb.Cell.ToText()
+	}
+	switch node := pb.Node.(type) {
+	case *Section:
+		return strings.Repeat("#", min(node.Level+1, 6)) + " " + strings.Join(strings.Fields(node.Title), " ")
+	case *ListItem:
+		depth := 0
+		for ancestor := range Ancestors(node) {
+			if _, ok := ancestor.(*ListItem); ok {
+				depth++
+			}
+		}
+		return strings.Repeat("  ", depth) + "- " + strings.Join(node.Sentences, " ")
+	case *Table:
+		return node.markdownRows(pb.RowStart, pb.RowEnd)
+	}
+	return pb.Node.ToText(false, false)
+}
+
+// ToText returns the text of the blocks on the page, one per line.
+func (p *Page) ToText() string {
+	texts := make([]string, 0, len(p.Blocks))
+	for _, block := range p.Blocks {
+		if text := block.Text(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ToMarkdown renders the page with sections as headings, list items as
+// bullets and tables as Markdown tables.
+func (p *Page) ToMarkdown() string {
+	var b strings.Builder
+	prevListItem := false
+	for _, block := range p.Blocks {
+		markdown := block.Markdown()
+		if markdown == "" {
+			continue
+		}
+		_, isListItem := block.Node.(*ListItem)
+		if b.Len() > 0 {
+			if isListItem && prevListItem {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(markdown)
+		prevListItem = isListItem
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package chipper
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDocumentPages(t *testing.T) {
+	doc := sampleDocument(t)
+	pages := doc.Pages()
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if len(pages[0].Blocks) != 3 || len(pages[1].Blocks) != 1 {
+		t.Fatalf("expected 3 blocks on page 0 and 1 on page 1, got %d and %d", len(pages[0].Blocks), len(pages[1].Blocks))
+	}
+
+	wantText := "Results of Operations\nRevenue grew in the quarter.\nCosts were flat.\nMobility revenue increased."
+	if got := pages[0].ToText(); got != wantText {
+		t.Fatalf("unexpected page text:\n%s", got)
+	}
+	wantMarkdown := "# Results of Operations\n\nRevenue grew in the quarter.\nCosts were flat.\n\n- Mobility revenue increased."
+	if got := pages[0].ToMarkdown(); got != wantMarkdown {
+		t.Fatalf("unexpected page Markdown:\n%s", got)
+	}
+	if got := pages[1].ToMarkdown(); got != doc.Tables()[0].(*Table).ToMarkdown() {
+		t.Fatalf("expected the table as Markdown, got:\n%s", got)
+	}
+}
+
+func TestDocumentPagesOrderAndCells(t *testing.T) {
+	var blocks []interface{}
+	err := json.Unmarshal([]byte(`[
+		{"tag": "para", "page_idx": 0, "block_idx": 0, "bbox": [300, 100, 500, 120], "sentences": ["Right column."]},
+		{"tag": "para", "page_idx": 0, "block_idx": 1, "bbox": [10, 100, 200, 120], "sentences": ["Left column."]},
+		{"tag": "para", "page_idx": 0, "block_idx": 2, "bbox": [10, 20, 500, 40], "sentences": ["Top."]},
+		{"tag": "para", "page_idx": 0, "block_idx": 3, "sentences": ["Unplaced."]},
+		{"tag": "table", "name": "", "page_idx": 1, "block_idx": 4, "bbox": [10, 500, 500, 780], "table_rows": [
+			{"type": "table_data_row", "cells": [
+				{"cell_value": "Note"},
+				{"cell_value": {"tag": "para", "page_idx": 2, "bbox": [10, 30, 400, 50], "sentences": ["Continued overleaf."]}}
+			]}
+		]}
+	]`), &blocks)
+	if err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+	doc.NumPages = 4
+	doc.PageWidth, doc.PageHeight = 612, 792
+
+	pages := doc.Pages()
+	if len(pages) != 4 || pages[3].Index != 3 || len(pages[3].Blocks) != 0 {
+		t.Fatalf("expected 4 pages with an empty last page, got %d", len(pages))
+	}
+	if pages[0].Width != 612 || pages[0].Height != 792 {
+		t.Fatalf("unexpected page size %vx%v", pages[0].Width, pages[0].Height)
+	}
+	if got := pages[0].ToText(); got != "Top.\nLeft column.\nRight column.\nUnplaced." {
+		t.Fatalf("blocks are not in reading order:\n%s", got)
+	}
+	if got := pages[1].ToText(); got != "| Note | Continued overleaf." {
+		t.Fatalf("unexpected table page text %q", got)
+	}
+	if len(pages[2].Blocks) != 1 || pages[2].Blocks[0].Cell == nil || pages[2].ToText() != "Continued overleaf." {
+		t.Fatalf("expected the cell on its own page, got %d blocks", len(pages[2].Blocks))
+	}
+}
+
+func TestDocumentPagesFixture(t *testing.T) {
+	data, err := os.ReadFile("../response.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	doc, err := NewDocumentFromResponse(data)
+	if err != nil {
+		t.Fatalf("NewDocumentFromResponse failed: %v", err)
+	}
+	if doc.NumPages != 105 || doc.PageWidth != 612 || doc.PageHeight != 792 {
+		t.Fatalf("unexpected page info: %d pages of %vx%v", doc.NumPages, doc.PageWidth, doc.PageHeight)
+	}
+	merged := doc.MergeSplitTables()
+
+	// The parser numbers the last page 105 although it reports 105 pages, so
+	// the view grows to keep every block.
+	pages := doc.Pages()
+	if len(pages) != 106 {
+		t.Fatalf("expected 106 pages, got %d", len(pages))
+	}
+	placed := 0
+	for _, page := range pages {
+		for i, block := range page.Blocks {
+			if block.Cell == nil && block.Node.NodePage() != page.Index {
+				if table, ok := block.Node.(*Table); !ok || len(table.Fragments) == 0 {
+					t.Fatalf("block %d placed on page %d", block.Node.NodeBlockIdx(), page.Index)
+				}
+			}
+			if i > 0 && readsBefore(block, page.Blocks[i-1]) {
+				t.Fatalf("page %d is not sorted at block %d", page.Index, i)
+			}
+			placed++
+		}
+	}
+	nodes := 0
+	for range doc.All() {
+		nodes++
+	}
+	if placed != nodes+merged {
+		t.Fatalf("placed %d blocks for %d nodes and %d merged tables", placed, nodes, merged)
+	}
+	if !strings.Contains(pages[12].ToMarkdown(), "| --- |") {
+		t.Fatalf("expected a Markdown table on page 12")
+	}
+
+	if _, err := NewDocumentFromResponse([]byte(`{"error": "bad file"}`)); err == nil {
+		t.Fatalf("expected an error for a response without blocks")
+	}
+}
+
+func TestPageBlocksMatchPages(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	// The spatial queries collect a single page, which must agree with the
+	// full page views.
+	for _, page := range doc.Pages() {
+		blocks := doc.pageBlocks(page.Index)
+		if len(blocks) != len(page.Blocks) {
+			t.Fatalf("page %d: %d blocks, Pages has %d", page.Index, len(blocks), len(page.Blocks))
+		}
+		for i := range blocks {
+			if blocks[i].Node != page.Blocks[i].Node || blocks[i].Cell != page.Blocks[i].Cell || blocks[i].RowStart != page.Blocks[i].RowStart {
+				t.Fatalf("page %d: block %d differs from Pages", page.Index, i)
+			}
+		}
+	}
+}
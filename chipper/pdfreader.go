@@ -3,6 +3,7 @@ package chipper
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -119,12 +120,38 @@ func (r *LayoutPDFReader) ReadPDF(pathOrURL string, contents []byte) (*Document,
 		return nil, err
 	}
 
-	var response map[string]interface{}
-	err = json.Unmarshal(parserResponse, &response)
+	doc, err := NewDocumentFromResponse(parserResponse)
 	if err != nil {
 		return nil, err
 	}
+	doc.SourceFile = pdfFile
+	return doc, nil
+}
 
-	blocks := response["return_dict"].(map[string]interface{})["result"].(map[string]interface{})["blocks"].([]interface{})
-	return NewDocument(blocks), nil
+// NewDocumentFromResponse builds a Document from the JSON response of the
+// parser API, including the page count and size.
+func NewDocumentFromResponse(parserResponse []byte) (*Document, error) {
+	var response struct {
+		ReturnDict struct {
+			NumPages float64   `json:"num_pages"`
+			PageDim  []float64 `json:"page_dim"`
+			Result   struct {
+				Blocks []interface{} `json:"blocks"`
+			} `json:"result"`
+		} `json:"return_dict"`
+	}
+	if err := json.Unmarshal(parserResponse, &response); err != nil {
+		return nil, err
+	}
+	if response.ReturnDict.Result.Blocks == nil {
+		return nil, fmt.Errorf("parser response has no blocks")
+	}
+
+	doc := NewDocument(response.ReturnDict.Result.Blocks)
+	doc.NumPages = int(response.ReturnDict.NumPages)
+	if len(response.ReturnDict.PageDim) == 2 {
+		doc.PageWidth = response.ReturnDict.PageDim[0]
+		doc.PageHeight = response.ReturnDict.PageDim[1]
+	}
+	return doc, nil
 }
@@ -0,0 +1,176 @@
+package chipper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type ContextFormat int
+
+const (
+	FormatText ContextFormat = iota
+	FormatMarkdown
+)
+
+type ContextOptions struct {
+	Format ContextFormat
+	// MaxTokens is the budget of the rendered context; zero means unlimited.
+	MaxTokens int
+	Tokenizer Tokenizer
+}
+
+type PromptContext struct {
+	Text string
+	// Chunks lists the chunks that made it into Text, in document order.
+	Chunks    []*Chunk
+	Tokens    int
+	Truncated bool
+}
+
+// BuildContext renders ranked retrieval results into prompt context. Chunks
+// are taken best first until the token budget is reached, skipping chunks
+// already covered by a selected chunk, then grouped by section in document
+// order and rendered with page citations such as [p.12].
+func BuildContext(ranked []*Chunk, opts ContextOptions) PromptContext {
+	tokenizer := opts.Tokenizer
+	if tokenizer == nil {
+		tokenizer = DefaultTokenizer
+	}
+
+	var selected []*Chunk
+	result := PromptContext{}
+	for _, chunk := range ranked {
+		if coveredBy(chunk, selected) {
+			continue
+		}
+		var kept []*Chunk
+		for _, other := range selected {
+			if !coveredBy(other, []*Chunk{chunk}) {
+				kept = append(kept, other)
+			}
+		}
+		candidate := append(kept, chunk)
+		text := renderContext(candidate, opts.Format)
+		tokens := tokenizer.CountTokens(text)
+		if opts.MaxTokens > 0 && tokens > opts.MaxTokens {
+			result.Truncated = true
+			break
+		}
+		selected = candidate
+		result.Text = text
+		result.Tokens = tokens
+	}
+	result.Chunks = sortDocumentOrder(selected)
+	return result
+}
+
+// coveredBy reports whether chunk duplicates or lies within one of others:
+// the same chunk, an overlapping piece of the same table, or a descendant of
+// a parent record.
+func coveredBy(chunk *Chunk, others []*Chunk) bool {
+	for _, other := range others {
+		if other == chunk || (other.ID == chunk.ID && other.Metadata.SourceFile == chunk.Metadata.SourceFile) {
+			return true
+		}
+		if chunk.Node != nil && other.Node == chunk.Node && !other.IsParent && !chunk.IsParent {
+			if _, ok := chunk.Node.(*Table); !ok || (chunk.RowStart < other.RowEnd && other.RowStart < chunk.RowEnd) {
+				return true
+			}
+		}
+		if !other.IsParent {
+			continue
+		}
+		if chunk.Node != nil && other.Node != nil {
+			if other.Node == chunk.Node && !chunk.IsParent {
+				return true
+			}
+			ancestor := blockOf(other.Node)
+			for parent := blockOf(chunk.Node).Parent; parent != nil; parent = blockOf(parent).Parent {
+				if blockOf(parent) == ancestor {
+					return true
+				}
+			}
+		} else if chunk.ParentID == other.ID && chunk.Metadata.SourceFile == other.Metadata.SourceFile {
+			return true
+		}
+	}
+	return false
+}
+
+// sortDocumentOrder orders chunks by block index within each source file,
+// keeping source files in order of first appearance. Chunks without a node,
+// such as those loaded from a saved index, keep their relative order.
+func sortDocumentOrder(chunks []*Chunk) []*Chunk {
+	ordered := append([]*Chunk(nil), chunks...)
+	sources := make(map[string]int)
+	for _, chunk := range ordered {
+		if _, ok := sources[chunk.Metadata.SourceFile]; !ok {
+			sources[chunk.Metadata.SourceFile] = len(sources)
+		}
+	}
+	blockIdx := func(chunk *Chunk) int {
+		if chunk.Node == nil {
+			return -1
+		}
+		return blockOf(chunk.Node).BlockIdx
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if sa, sb := sources[a.Metadata.SourceFile], sources[b.Metadata.SourceFile]; sa != sb {
+			return sa < sb
+		}
+		if ai, bi := blockIdx(a), blockIdx(b); ai != bi {
+			return ai < bi
+		}
+		return a.RowStart < b.RowStart
+	})
+	return ordered
+}
+
+func pageCitation(pages []int) string {
+	if len(pages) == 0 {
+		return ""
+	}
+	first, last := pages[0]+1, pages[len(pages)-1]+1
+	if first == last {
+		return fmt.Sprintf("[p.%d]", first)
+	}
+	return fmt.Sprintf("[p.%d-%d]", first, last)
+}
+
+func renderContext(chunks []*Chunk, format ContextFormat) string {
+	var groups []string
+	var current string
+	var b strings.Builder
+	for i, chunk := range sortDocumentOrder(chunks) {
+		section := strings.Join(chunk.Metadata.SectionPath, " > ")
+		if i == 0 || section != current {
+			if b.Len() > 0 {
+				groups = append(groups, strings.TrimSpace(b.String()))
+				b.Reset()
+			}
+			current = section
+			if section != "" {
+				if format == FormatMarkdown {
+					b.WriteString("## " + section + "\n\n")
+				} else {
+					b.WriteString(section + "\n")
+				}
+			}
+		}
+		text := strings.TrimSpace(chunk.Text)
+		if citation := pageCitation(chunk.Metadata.Pages); citation != "" {
+			text += " " + citation
+		}
+		b.WriteString(text + "\n\n")
+	}
+	if b.Len() > 0 {
+		groups = append(groups, strings.TrimSpace(b.String()))
+	}
+	separator := "\n\n"
+	if format == FormatMarkdown {
+		separator = "\n\n---\n\n"
+	}
+	return strings.Join(groups, separator)
+}
@@ -0,0 +1,45 @@
+package chipper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildContext(t *testing.T) {
+	doc := sampleDocument(t)
+	chunks := doc.ChunkRecords(ChunkOptions{Mode: ChunkModeHierarchical, TableStrategy: TableChunkRows, MaxTokens: 32})
+	lookup := ChunkLookup(chunks)
+
+	ranked := []*Chunk{lookup["chunk-3-rows-2-4"], lookup["chunk-1"], lookup["chunk-3-rows-2-4"], lookup["chunk-2"]}
+	built := BuildContext(ranked, ContextOptions{Format: FormatMarkdown})
+	if len(built.Chunks) != 3 || built.Truncated {
+		t.Fatalf("expected 3 deduplicated chunks, got %d", len(built.Chunks))
+	}
+	if built.Chunks[0].ID != "chunk-1" || built.Chunks[2].ID != "chunk-3-rows-2-4" {
+		t.Fatalf("chunks are not in document order: %s, %s, %s", built.Chunks[0].ID, built.Chunks[1].ID, built.Chunks[2].ID)
+	}
+	if strings.Count(built.Text, "## Results of Operations") != 1 {
+		t.Fatalf("expected one section heading:\n%s", built.Text)
+	}
+	if !strings.Contains(built.Text, "Costs were flat. [p.1]") || !strings.Contains(built.Text, "[p.2]") {
+		t.Fatalf("missing page citations:\n%s", built.Text)
+	}
+
+	t.Run("ParentCoversChildren", func(t *testing.T) {
+		built := BuildContext([]*Chunk{lookup["chunk-1"], lookup["parent-0"], lookup["chunk-2"]}, ContextOptions{})
+		if len(built.Chunks) != 1 || built.Chunks[0].ID != "parent-0" {
+			t.Fatalf("expected only the parent record, got %d chunks", len(built.Chunks))
+		}
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		budget := DefaultTokenizer.CountTokens(BuildContext(ranked[:2], ContextOptions{}).Text)
+		built := BuildContext(ranked, ContextOptions{MaxTokens: budget})
+		if !built.Truncated || len(built.Chunks) != 2 {
+			t.Fatalf("expected the budget to keep 2 chunks, got %d", len(built.Chunks))
+		}
+		if built.Tokens > budget {
+			t.Fatalf("context uses %d tokens, budget is %d", built.Tokens, budget)
+		}
+	})
+}
@@ -0,0 +1,543 @@
+package chipper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Selector is a compiled CSS-like query over the document tree, see
+// ParseSelector for the syntax. A Selector is safe for concurrent use.
+type Selector struct {
+	source string
+	alts   []complexSelector
+}
+
+// SelectorError reports an invalid selector and the byte offset at which
+// parsing failed.
+type SelectorError struct {
+	Selector string
+	Offset   int
+	Msg      string
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid selector %q at offset %d: %s", e.Selector, e.Offset, e.Msg)
+}
+
+// complexSelector is a chain of compound selectors; combinators[i] joins
+// parts[i] and parts[i+1] and is one of ' ', '>', '+' or '~'.
+type complexSelector struct {
+	parts       []compoundSelector
+	combinators []byte
+}
+
+type compoundSelector struct {
+	// tag is the required NodeTag, or "" for any node.
+	tag     string
+	filters []func(BlockInterface) bool
+}
+
+// selectorTags maps the node type names of selectors to block tags.
+var selectorTags = map[string]string{
+	"section":   "header",
+	"header":    "header",
+	"para":      "para",
+	"paragraph": "para",
+	"list_item": "list_item",
+	"table":     "table",
+}
+
+// selectorAttributes returns the value of the attributes selectors can test.
+var selectorAttributes = map[string]func(BlockInterface) string{
+	"title": func(node BlockInterface) string {
+		switch n := node.(type) {
+		case *Section:
+			return n.Title
+		case *Table:
+			return n.Name
+		}
+		return ""
+	},
+	"name": func(node BlockInterface) string {
+		if table, ok := node.(*Table); ok {
+			return table.Name
+		}
+		return ""
+	},
+	"text": nodeText,
+	"tag":  BlockInterface.NodeTag,
+	"level": func(node BlockInterface) string {
+		return strconv.Itoa(node.NodeLevel())
+	},
+	"page": func(node BlockInterface) string {
+		return strconv.Itoa(node.NodePage())
+	},
+	"block_idx": func(node BlockInterface) string {
+		return strconv.Itoa(node.NodeBlockIdx())
+	},
+}
+
+// ParseSelector compiles a selector. The syntax is a subset of CSS:
+//
+//   - node types section (or header), para (or paragraph), list_item, table
+//     and * for any node;
+//   - attributes title, name, text, tag, level, page and block_idx, tested
+//     with [attr] (non-empty), [attr="v"] (equals), [attr^="v"] (starts
+//     with), [attr$="v"] (ends with), [attr*="v"] (contains) or [attr~="v"]
+//     (contains every word of v). Comparisons ignore case and collapse
+//     whitespace;
+//   - pseudo-classes :page(n) and :page(first-last) on zero-based page
+//     indices, :level(n) and :level(min-max), :first-child, :last-child and
+//     :not(selector);
+//   - the descendant (space), child (>), adjacent sibling (+) and general
+//     sibling (~) combinators, and comma separated alternatives.
+//
+// For example `section[title~="Risk Factors"] > para` or `table:page(10-20)`.
+func ParseSelector(selector string) (*Selector, error) {
+	p := &selectorParser{src: selector}
+	alts, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return &Selector{source: selector, alts: alts}, nil
+}
+
+// MustParseSelector is like ParseSelector but panics on an invalid selector.
+func MustParseSelector(selector string) *Selector {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+// Match reports whether node matches the selector. Ancestors and siblings
+// anywhere in the tree count for combinators.
+func (s *Selector) Match(node BlockInterface) bool {
+	for _, alt := range s.alts {
+		if alt.matches(node, len(alt.parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the descendants of root matching the selector in document
+// order.
+func (s *Selector) Select(root BlockInterface) []BlockInterface {
+	var nodes []BlockInterface
+	for node := range Descendants(root) {
+		if s.Match(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Query returns the nodes of the document matching selector in document
+// order. Use ParseSelector and Select to run a selector repeatedly.
+func (d *Document) Query(selector string) ([]BlockInterface, error) {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return d.Select(s), nil
+}
+
+func (d *Document) Select(s *Selector) []BlockInterface {
+	return s.Select(d.rootNode)
+}
+
+func (c complexSelector) matches(node BlockInterface, i int) bool {
+	if !c.parts[i].matches(node) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch c.combinators[i-1] {
+	case '>':
+		parent := node.ParentNode()
+		return parent != nil && c.matches(parent, i-1)
+	case ' ':
+		for parent := node.ParentNode(); parent != nil; parent = parent.ParentNode() {
+			if c.matches(parent, i-1) {
+				return true
+			}
+		}
+	case '+':
+		siblings, idx := siblingsOf(node)
+		return idx > 0 && c.matches(siblings[idx-1], i-1)
+	case '~':
+		siblings, idx := siblingsOf(node)
+		for j := idx - 1; j >= 0; j-- {
+			if c.matches(siblings[j], i-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c compoundSelector) matches(node BlockInterface) bool {
+	// The untagged root of the tree holds the document, not a block of it.
+	if node.ParentNode() == nil {
+		return false
+	}
+	if c.tag != "" && node.NodeTag() != c.tag {
+		return false
+	}
+	for _, filter := range c.filters {
+		if !filter(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// siblingsOf returns the children of the parent of node and the position of
+// node among them, or -1 for the root.
+func siblingsOf(node BlockInterface) ([]BlockInterface, int) {
+	parent := node.ParentNode()
+	if parent == nil {
+		return nil, -1
+	}
+	return parent.ChildNodes(), node.Index()
+}
+
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return &SelectorError{Selector: p.src, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n') {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) ident() string {
+	return strings.ToLower(p.word("_-"))
+}
+
+// word reads letters, digits and the runes of punct, decoding the input as
+// UTF-8 so that non-ASCII letters are read whole.
+func (p *selectorParser) word(punct string) string {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(punct, r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos]
+}
+
+// parseList parses comma separated complex selectors up to the end of the
+// input or a closing parenthesis.
+func (p *selectorParser) parseList() ([]complexSelector, error) {
+	var alts []complexSelector
+	for {
+		alt, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, alt)
+		if p.peek() != ',' {
+			return alts, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	var c complexSelector
+	p.skipSpace()
+	compound, err := p.parseCompound()
+	if err != nil {
+		return c, err
+	}
+	c.parts = append(c.parts, compound)
+	for {
+		spaced := p.skipSpace()
+		if p.eof() || p.peek() == ',' || p.peek() == ')' {
+			return c, nil
+		}
+		combinator := byte(' ')
+		switch p.peek() {
+		case '>', '+', '~':
+			combinator = p.peek()
+			p.pos++
+			p.skipSpace()
+		default:
+			if !spaced {
+				return c, p.errorf("unexpected %q", p.peek())
+			}
+		}
+		compound, err := p.parseCompound()
+		if err != nil {
+			return c, err
+		}
+		c.combinators = append(c.combinators, combinator)
+		c.parts = append(c.parts, compound)
+	}
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var c compoundSelector
+	start := p.pos
+	if p.peek() == '*' {
+		p.pos++
+	} else if name := p.ident(); name != "" {
+		tag, ok := selectorTags[name]
+		if !ok {
+			p.pos = start
+			return c, p.errorf("unknown node type %q", name)
+		}
+		c.tag = tag
+	}
+	for {
+		var filter func(BlockInterface) bool
+		var err error
+		switch p.peek() {
+		case '[':
+			filter, err = p.parseAttribute()
+		case ':':
+			filter, err = p.parsePseudo()
+		default:
+			if p.pos == start {
+				if p.eof() {
+					return c, p.errorf("expected a node type, attribute or pseudo-class")
+				}
+				return c, p.errorf("unexpected %q", p.peek())
+			}
+			return c, nil
+		}
+		if err != nil {
+			return c, err
+		}
+		c.filters = append(c.filters, filter)
+	}
+}
+
+func (p *selectorParser) parseAttribute() (func(BlockInterface) bool, error) {
+	p.pos++
+	p.skipSpace()
+	nameStart := p.pos
+	name := p.ident()
+	if name == "" {
+		return nil, p.errorf("expected an attribute name")
+	}
+	attribute, ok := selectorAttributes[name]
+	if !ok {
+		p.pos = nameStart
+		return nil, p.errorf("unknown attribute %q", name)
+	}
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return func(node BlockInterface) bool {
+			return strings.TrimSpace(attribute(node)) != ""
+		}, nil
+	}
+
+	op := ""
+	for _, candidate := range []string{"=", "^=", "$=", "*=", "~="} {
+		if strings.HasPrefix(p.src[p.pos:], candidate) {
+			op = candidate
+		}
+	}
+	if op == "" {
+		return nil, p.errorf("expected an attribute operator or ]")
+	}
+	p.pos += len(op)
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, p.errorf("expected ] to close the attribute selector")
+	}
+	p.pos++
+
+	want := normalizeSelectorText(value)
+	words := strings.Fields(want)
+	return func(node BlockInterface) bool {
+		got := normalizeSelectorText(attribute(node))
+		switch op {
+		case "=":
+			return got == want
+		case "^=":
+			return strings.HasPrefix(got, want)
+		case "$=":
+			return strings.HasSuffix(got, want)
+		case "*=":
+			return strings.Contains(got, want)
+		}
+		gotWords := strings.Fields(got)
+		for _, word := range words {
+			found := false
+			for _, gotWord := range gotWords {
+				found = found || strings.Trim(gotWord, ".,;:()\"'") == word
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// parseValue reads a quoted string with backslash escapes or a bare word such
+// as 2 or Revenue.
+func (p *selectorParser) parseValue() (string, error) {
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		value := p.word("._-")
+		if value == "" {
+			return "", p.errorf("expected an attribute value")
+		}
+		return value, nil
+	}
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && !p.eof():
+			b.WriteByte(p.peek())
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *selectorParser) parsePseudo() (func(BlockInterface) bool, error) {
+	p.pos++
+	nameStart := p.pos
+	name := p.ident()
+	switch name {
+	case "first-child", "last-child":
+		first := name == "first-child"
+		return func(node BlockInterface) bool {
+			siblings, idx := siblingsOf(node)
+			if first {
+				return idx == 0
+			}
+			return idx >= 0 && idx == len(siblings)-1
+		}, nil
+	case "page", "level":
+		if p.peek() != '(' {
+			return nil, p.errorf(":%s needs a number or range such as :%s(2) or :%s(2-4)", name, name, name)
+		}
+		p.pos++
+		argStart := p.pos
+		end := strings.IndexByte(p.src[p.pos:], ')')
+		if end < 0 {
+			return nil, p.errorf("expected ) to close :%s", name)
+		}
+		first, last, err := parseSelectorRange(p.src[p.pos : p.pos+end])
+		if err != nil {
+			p.pos = argStart
+			return nil, p.errorf("invalid :%s argument: %v", name, err)
+		}
+		p.pos += end + 1
+		if name == "level" {
+			return func(node BlockInterface) bool {
+				return node.NodeLevel() >= first && node.NodeLevel() <= last
+			}, nil
+		}
+		return func(node BlockInterface) bool {
+			pages := []int{node.NodePage()}
+			if table, ok := node.(*Table); ok {
+				pages = table.Pages()
+			}
+			for _, page := range pages {
+				if page >= first && page <= last {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "not":
+		if p.peek() != '(' {
+			return nil, p.errorf(":not needs a selector such as :not(table)")
+		}
+		p.pos++
+		alts, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ) to close :not")
+		}
+		p.pos++
+		inner := &Selector{alts: alts}
+		return func(node BlockInterface) bool {
+			return !inner.Match(node)
+		}, nil
+	case "":
+		return nil, p.errorf("expected a pseudo-class name")
+	}
+	p.pos = nameStart
+	return nil, p.errorf("unknown pseudo-class %q", name)
+}
+
+// parseSelectorRange parses "n" or "first-last" into an inclusive range.
+func parseSelectorRange(arg string) (int, int, error) {
+	firstText, lastText, isRange := strings.Cut(arg, "-")
+	first, err := strconv.Atoi(strings.TrimSpace(firstText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a number or range", arg)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	last, err := strconv.Atoi(strings.TrimSpace(lastText))
+	if err != nil || last < first {
+		return 0, 0, fmt.Errorf("%q is not a number or range", arg)
+	}
+	return first, last, nil
+}
+
+func normalizeSelectorText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
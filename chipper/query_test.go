@@ -0,0 +1,143 @@
+package chipper
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	doc := sampleDocument(t)
+
+	tests := []struct {
+		selector string
+		want     []int
+	}{
+		{`section`, []int{0}},
+		{`section[title~="operations results"] > para`, []int{1}},
+		{`section > *`, []int{1, 2, 3}},
+		{`para + list_item`, []int{2}},
+		{`para ~ table`, []int{3}},
+		{`list_item ~ para`, nil},
+		{`table:page(1)`, []int{3}},
+		{`*:page(0-0):not(section)`, []int{1, 2}},
+		{`*:last-child, *:level(1):first-child`, []int{0, 1, 3}},
+		{`[text*="MOBILITY revenue"]`, []int{2}},
+		{`table[name^="Revenue by"]`, []int{3}},
+		{`[title$="(in millions)"]`, []int{3}},
+		{`section[title="Results  of operations"] table`, []int{3}},
+		{`section[title="Results"]`, nil},
+		{`para:not(section > *)`, nil},
+	}
+	for _, tt := range tests {
+		nodes, err := doc.Query(tt.selector)
+		if err != nil {
+			t.Errorf("%s: %v", tt.selector, err)
+			continue
+		}
+		var got []int
+		for _, node := range nodes {
+			got = append(got, node.NodeBlockIdx())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got blocks %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorReuse(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	selector := MustParseSelector(`section[title~="Risk Factors"] > para`)
+	paras := doc.Select(selector)
+	if len(paras) == 0 {
+		t.Fatalf("expected paragraphs under the risk factor sections")
+	}
+	for _, node := range paras {
+		section, ok := node.ParentNode().(*Section)
+		if _, isPara := node.(*Paragraph); !isPara || !ok {
+			t.Fatalf("block %d is not a paragraph directly in a section", node.NodeBlockIdx())
+		}
+		if !selector.Match(node) || len(doc.Select(MustParseSelector(`section[title="`+section.Title+`"]`))) == 0 {
+			t.Fatalf("selector does not match its own results")
+		}
+	}
+
+	tables := doc.Select(MustParseSelector(`table:page(10-20)`))
+	for _, node := range tables {
+		if page := node.NodePage(); page < 10 || page > 20 {
+			t.Fatalf("table %d on page %d outside 10-20", node.NodeBlockIdx(), page)
+		}
+	}
+	if len(tables) == 0 {
+		t.Fatalf("expected tables on pages 10-20")
+	}
+	for _, node := range doc.Select(MustParseSelector(`list_item:level(2)`)) {
+		if node.NodeLevel() != 2 {
+			t.Fatalf("list item %d has level %d", node.NodeBlockIdx(), node.NodeLevel())
+		}
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		selector string
+		offset   int
+		msg      string
+	}{
+		{``, 0, "expected a node type, attribute or pseudo-class"},
+		{`tabel`, 0, `unknown node type "tabel"`},
+		{`section >`, 9, "expected a node type, attribute or pseudo-class"},
+		{`table,`, 6, "expected a node type, attribute or pseudo-class"},
+		{`section[title~="Risk]`, 15, "unterminated string"},
+		{`section[title="Risk"`, 20, "expected ] to close the attribute selector"},
+		{`section[owner="x"]`, 8, `unknown attribute "owner"`},
+		{`section[title=="x"]`, 14, "expected an attribute value"},
+		{`section[title=Выручка—2022]`, 28, "expected ] to close the attribute selector"},
+		{`table:page(ten)`, 11, `invalid :page argument: "ten" is not a number or range`},
+		{`table:page(20-10)`, 11, `invalid :page argument: "20-10" is not a number or range`},
+		{`table:page`, 10, ":page needs a number or range such as :page(2) or :page(2-4)"},
+		{`table:nth(2)`, 6, `unknown pseudo-class "nth"`},
+		{`para:not(table`, 14, "expected ) to close :not"},
+		{`para)`, 4, `unexpected ')'`},
+	}
+	for _, tt := range tests {
+		_, err := ParseSelector(tt.selector)
+		var selectorErr *SelectorError
+		if !errors.As(err, &selectorErr) {
+			t.Errorf("%s: expected a SelectorError, got %v", tt.selector, err)
+			continue
+		}
+		if selectorErr.Offset != tt.offset || selectorErr.Msg != tt.msg {
+			t.Errorf("%s: got %q at %d, want %q at %d", tt.selector, selectorErr.Msg, selectorErr.Offset, tt.msg, tt.offset)
+		}
+	}
+}
+
+func TestSelectorNonASCII(t *testing.T) {
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "header", "level": 0, "block_idx": 0, "sentences": ["Выручка"]},
+		{"tag": "para", "level": 1, "block_idx": 1, "sentences": ["Umsatzerlöse stiegen."]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+
+	for selector, want := range map[string]int{
+		`section[title=Выручка] > para`: 1,
+		`para[text*=Umsatzerlöse]`:      1,
+	} {
+		nodes, err := doc.Query(selector)
+		if err != nil {
+			t.Errorf("%s: %v", selector, err)
+			continue
+		}
+		if len(nodes) != 1 || nodes[0].NodeBlockIdx() != want {
+			t.Errorf("%s: got %d nodes, want block %d", selector, len(nodes), want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package chipper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Record is one data row of a table keyed by column label.
+type Record struct {
+	// Row is the index of the row in Table.Rows.
+	Row int
+	// Label is the text of the first column when it holds row labels.
+	Label string
+	// Section is the label of the closest full_row above, such as "Assets".
+	Section string
+	Values  map[string]CellValue
+}
+
+// RecordColumns returns the keys used in Record.Values, in column order:
+// the column labels, with "Column N" for unlabelled columns and a numeric
+// suffix on repeated labels. The row label column is left out when the first
+// column holds row labels.
+func (t *Table) RecordColumns() []string {
+	labels := t.ColumnLabels()
+	seen := make(map[string]int)
+	keys := make([]string, 0, len(labels))
+	for c, label := range labels {
+		if c == 0 && t.hasRowLabels() {
+			continue
+		}
+		if label == "" {
+			label = fmt.Sprintf("Column %d", c+1)
+		}
+		seen[label]++
+		if seen[label] > 1 {
+			label = fmt.Sprintf("%s (%d)", label, seen[label])
+		}
+		keys = append(keys, label)
+	}
+	return keys
+}
+
+// hasRowLabels reports whether the first column of the data rows is mostly
+// text, as in financial statements, rather than values.
+func (t *Table) hasRowLabels() bool {
+	if t.NumCols() < 2 {
+		return false
+	}
+	text, values := 0, 0
+	for _, row := range t.Rows {
+		if row.IsFullRow() || len(row.Cells) == 0 {
+			continue
+		}
+		switch row.Cells[0].Value().Kind {
+		case CellText:
+			text++
+		case CellEmpty:
+		default:
+			values++
+		}
+	}
+	return text > values
+}
+
+// Records returns the data rows as key/value records; full rows become the
+// Section of the records below them.
+func (t *Table) Records() []Record {
+	keys := t.RecordColumns()
+	rowLabels := t.hasRowLabels()
+	grid := t.Grid()
+
+	var records []Record
+	section := ""
+	for i, slots := range grid[len(t.Headers):] {
+		if t.Rows[i].IsFullRow() {
+			section = strings.TrimSpace(t.Rows[i].Cells[0].ToText())
+			continue
+		}
+		record := Record{Row: i, Section: section, Values: make(map[string]CellValue, len(keys))}
+		k := 0
+		for c, slot := range slots {
+			if c == 0 && rowLabels {
+				record.Label = strings.Join(strings.Fields(slot.Text()), " ")
+				continue
+			}
+			record.Values[keys[k]] = t.scaledValue(slot)
+			k++
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Lookup finds the value at the row labelled rowLabel and the column labelled
+// colLabel, so Lookup("Revenue", "2022") answers "revenue for 2022". Labels
+// are compared case-insensitively, preferring exact matches over labels that
+// merely contain the query. The row may also be named "Section > Label".
+func (t *Table) Lookup(rowLabel, colLabel string) (CellValue, bool) {
+	records := t.Records()
+	rowLabels := make([]string, len(records))
+	for i, record := range records {
+		rowLabels[i] = record.Label
+		if record.Section != "" && strings.Contains(rowLabel, ">") {
+			rowLabels[i] = record.Section + " > " + record.Label
+		}
+	}
+	row := bestLabelMatch(rowLabels, rowLabel)
+	col := bestLabelMatch(t.RecordColumns(), colLabel)
+	if row < 0 || col < 0 {
+		return CellValue{}, false
+	}
+	value, ok := records[row].Values[t.RecordColumns()[col]]
+	return value, ok
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func bestLabelMatch(labels []string, query string) int {
+	query = normalizeLabel(query)
+	if query == "" {
+		return -1
+	}
+	for i, label := range labels {
+		if normalizeLabel(label) == query {
+			return i
+		}
+	}
+	for i, label := range labels {
+		if strings.Contains(normalizeLabel(label), query) {
+			return i
+		}
+	}
+	return -1
+}
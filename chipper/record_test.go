@@ -0,0 +1,53 @@
+package chipper
+
+import "testing"
+
+func TestTableRecords(t *testing.T) {
+	table := newTestTable(t, `{"tag": "table", "name": "Condensed balance (In millions)", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "As of", "col_span": 2}]},
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "December 31, 2021"}, {"cell_value": "March 31, 2022"}]},
+		{"type": "full_row", "cell_value": "Assets", "col_span": 3},
+		{"type": "table_data_row", "cells": [{"cell_value": "Cash and cash equivalents"}, {"cell_value": "$ 4,295"}, {"cell_value": "$ 4,836"}]},
+		{"type": "full_row", "cell_value": "Liabilities", "col_span": 3},
+		{"type": "table_data_row", "cells": [{"cell_value": "Accounts payable"}, {"cell_value": "860"}, {"cell_value": "(12)"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Other"}, {"cell_value": "—"}, {"cell_value": "1"}]}
+	]}`)
+
+	columns := table.RecordColumns()
+	if len(columns) != 2 || columns[0] != "As of December 31, 2021" || columns[1] != "As of March 31, 2022" {
+		t.Fatalf("unexpected record columns: %q", columns)
+	}
+
+	records := table.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	cash := records[0]
+	if cash.Label != "Cash and cash equivalents" || cash.Section != "Assets" || cash.Row != 1 {
+		t.Fatalf("unexpected first record: %+v", cash)
+	}
+	if value := cash.Values["As of March 31, 2022"]; value.Kind != CellCurrency || value.Scaled() != 4836e6 {
+		t.Fatalf("unexpected cash value: %+v", value)
+	}
+	if records[1].Section != "Liabilities" || records[2].Values["As of December 31, 2021"].Kind != CellEmpty {
+		t.Fatalf("unexpected liability records: %+v", records[1:])
+	}
+
+	if value, ok := table.Lookup("accounts payable", "2022"); !ok || value.Number != -12 {
+		t.Fatalf("Lookup(accounts payable, 2022) = %+v, %v", value, ok)
+	}
+	if value, ok := table.Lookup("Assets > Cash", "December 31, 2021"); !ok || value.Number != 4295 {
+		t.Fatalf("Lookup(Assets > Cash, December 31, 2021) = %+v, %v", value, ok)
+	}
+	if _, ok := table.Lookup("Goodwill", "2022"); ok {
+		t.Fatal("expected no match for a missing row")
+	}
+
+	numeric := newTestTable(t, `{"tag": "table", "name": "", "table_rows": [
+		{"type": "table_data_row", "cells": [{"cell_value": "1"}, {"cell_value": "2"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "3"}, {"cell_value": "4"}]}
+	]}`)
+	if records := numeric.Records(); records[1].Label != "" || records[1].Values["Column 1"].Number != 3 {
+		t.Fatalf("numeric first column should not be used as row labels: %+v", records)
+	}
+}
@@ -0,0 +1,195 @@
+package chipper
+
+import (
+	"math"
+	"sort"
+)
+
+// Rect is an area of a page in PDF points, with the origin at the top left
+// corner of the page and y growing downwards as in Block.Bbox.
+type Rect struct {
+	Page int
+	X0   float64
+	Y0   float64
+	X1   float64
+	Y1   float64
+}
+
+// RectFromBbox builds the Rect of a [x0, y0, x1, y1] bounding box on page.
+// It reports false when bbox does not have four values.
+func RectFromBbox(page int, bbox []float64) (Rect, bool) {
+	if len(bbox) != 4 {
+		return Rect{}, false
+	}
+	return Rect{Page: page, X0: bbox[0], Y0: bbox[1], X1: bbox[2], Y1: bbox[3]}.Canon(), true
+}
+
+// Rect returns the bounding box of the block, or false when it has none.
+func (b *Block) Rect() (Rect, bool) {
+	return RectFromBbox(b.PageIdx, b.Bbox)
+}
+
+// Rect returns the bounding box of the block on its page.
+func (pb PageBlock) Rect() (Rect, bool) {
+	return RectFromBbox(pb.Page, pb.Bbox)
+}
+
+// Canon returns r with its corners swapped as needed so X0 <= X1 and Y0 <= Y1.
+func (r Rect) Canon() Rect {
+	if r.X0 > r.X1 {
+		r.X0, r.X1 = r.X1, r.X0
+	}
+	if r.Y0 > r.Y1 {
+		r.Y0, r.Y1 = r.Y1, r.Y0
+	}
+	return r
+}
+
+func (r Rect) Width() float64 {
+	return r.X1 - r.X0
+}
+
+func (r Rect) Height() float64 {
+	return r.Y1 - r.Y0
+}
+
+func (r Rect) Area() float64 {
+	return r.Width() * r.Height()
+}
+
+func (r Rect) Center() (float64, float64) {
+	return (r.X0 + r.X1) / 2, (r.Y0 + r.Y1) / 2
+}
+
+// Contains reports whether the point x, y lies in r, edges included.
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.X0 && x <= r.X1 && y >= r.Y0 && y <= r.Y1
+}
+
+// Intersects reports whether r and o are on the same page and overlap or
+// touch.
+func (r Rect) Intersects(o Rect) bool {
+	return r.Page == o.Page && r.X0 <= o.X1 && o.X0 <= r.X1 && r.Y0 <= o.Y1 && o.Y0 <= r.Y1
+}
+
+// Union returns the smallest Rect covering r and o, on the page of r.
+func (r Rect) Union(o Rect) Rect {
+	return Rect{Page: r.Page, X0: min(r.X0, o.X0), Y0: min(r.Y0, o.Y0), X1: max(r.X1, o.X1), Y1: max(r.Y1, o.Y1)}
+}
+
+// Normalize maps r from points to fractions of a page of the given size, so
+// a page_dim of [612, 792] turns x 306 into 0.5. A zero size leaves r as is.
+func (r Rect) Normalize(width, height float64) Rect {
+	if width <= 0 || height <= 0 {
+		return r
+	}
+	return Rect{Page: r.Page, X0: r.X0 / width, Y0: r.Y0 / height, X1: r.X1 / width, Y1: r.Y1 / height}
+}
+
+// Denormalize is the inverse of Normalize, mapping fractions of the page back
+// to points.
+func (r Rect) Denormalize(width, height float64) Rect {
+	if width <= 0 || height <= 0 {
+		return r
+	}
+	return Rect{Page: r.Page, X0: r.X0 * width, Y0: r.Y0 * height, X1: r.X1 * width, Y1: r.Y1 * height}
+}
+
+// NormalizeRect normalizes r against the page size of the document.
+func (d *Document) NormalizeRect(r Rect) Rect {
+	return r.Normalize(d.PageWidth, d.PageHeight)
+}
+
+// DenormalizeRect maps a normalized r back to points on the document pages,
+// such as a selection made on a rendered page image.
+func (d *Document) DenormalizeRect(r Rect) Rect {
+	return r.Denormalize(d.PageWidth, d.PageHeight)
+}
+
+type Direction int
+
+const (
+	DirectionUp Direction = iota
+	DirectionDown
+	DirectionLeft
+	DirectionRight
+)
+
+// BlocksInRect returns the blocks of the page of rect whose bounding boxes
+// intersect it, in reading order. Blocks without a bounding box are skipped.
+func (d *Document) BlocksInRect(rect Rect) []PageBlock {
+	rect = rect.Canon()
+	var blocks []PageBlock
+	for _, block := range d.pageBlocks(rect.Page) {
+		if r, ok := block.Rect(); ok && r.Intersects(rect) {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// BlocksAt returns the blocks of page containing the point x, y, smallest
+// first, so a table cell placed on the page comes before its table.
+func (d *Document) BlocksAt(page int, x, y float64) []PageBlock {
+	var blocks []PageBlock
+	for _, block := range d.pageBlocks(page) {
+		if r, ok := block.Rect(); ok && r.Contains(x, y) {
+			blocks = append(blocks, block)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		ri, _ := blocks[i].Rect()
+		rj, _ := blocks[j].Rect()
+		return ri.Area() < rj.Area()
+	})
+	return blocks
+}
+
+// Nearest returns the closest block on the same page as node lying entirely
+// in direction from it. Blocks in line with node, such as those overlapping
+// its columns when looking down, win over blocks off to the side; among
+// those the smallest gap wins, measured along direction plus across it. It
+// reports false when node has no bounding box or nothing lies in that
+// direction.
+func (d *Document) Nearest(node BlockInterface, direction Direction) (PageBlock, bool) {
+	from, ok := RectFromBbox(node.NodePage(), node.NodeBbox())
+	if !ok {
+		return PageBlock{}, false
+	}
+	var nearest PageBlock
+	best := math.Inf(1)
+	bestInLine := false
+	for _, block := range d.pageBlocks(from.Page) {
+		r, ok := block.Rect()
+		if !ok || (block.Node == node && block.Cell == nil) {
+			continue
+		}
+		var along, across float64
+		switch direction {
+		case DirectionUp:
+			along, across = from.Y0-r.Y1, spanGap(from.X0, from.X1, r.X0, r.X1)
+		case DirectionDown:
+			along, across = r.Y0-from.Y1, spanGap(from.X0, from.X1, r.X0, r.X1)
+		case DirectionLeft:
+			along, across = from.X0-r.X1, spanGap(from.Y0, from.Y1, r.Y0, r.Y1)
+		case DirectionRight:
+			along, across = r.X0-from.X1, spanGap(from.Y0, from.Y1, r.Y0, r.Y1)
+		}
+		if along < 0 {
+			continue
+		}
+		inLine := across == 0
+		if distance := along + across; inLine && !bestInLine || inLine == bestInLine && distance < best {
+			best = distance
+			bestInLine = inLine
+			nearest = block
+		}
+	}
+	return nearest, !math.IsInf(best, 1)
+}
+
+// spanGap returns the distance between the intervals [a0, a1] and [b0, b1],
+// zero when they overlap.
+func spanGap(a0, a1, b0, b1 float64) float64 {
+	return max(0, b0-a1, a0-b1)
+}
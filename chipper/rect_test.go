@@ -0,0 +1,123 @@
+package chipper
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func spatialDocument(t *testing.T) *Document {
+	t.Helper()
+	var blocks []interface{}
+	err := json.Unmarshal([]byte(`[
+		{"tag": "header", "level": 0, "page_idx": 0, "block_idx": 0, "bbox": [50, 40, 300, 60], "sentences": ["Overview"]},
+		{"tag": "para", "level": 1, "page_idx": 0, "block_idx": 1, "bbox": [50, 80, 290, 200], "sentences": ["Left column."]},
+		{"tag": "para", "level": 1, "page_idx": 0, "block_idx": 2, "bbox": [320, 80, 560, 200], "sentences": ["Right column."]},
+		{"tag": "para", "level": 1, "page_idx": 0, "block_idx": 3, "bbox": [320, 260, 560, 300], "sentences": ["Right, lower."]},
+		{"tag": "table", "name": "Totals", "level": 1, "page_idx": 0, "block_idx": 4, "bbox": [50, 400, 560, 600], "table_rows": [
+			{"type": "table_data_row", "cells": [
+				{"cell_value": "Total"},
+				{"cell_value": {"tag": "para", "page_idx": 1, "bbox": [60, 20, 200, 40], "sentences": ["See next page."]}}
+			]}
+		]},
+		{"tag": "para", "level": 1, "page_idx": 1, "block_idx": 5, "bbox": [50, 50, 560, 90], "sentences": ["Next page."]}
+	]`), &blocks)
+	if err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+	doc.PageWidth, doc.PageHeight = 612, 792
+	return doc
+}
+
+func TestRect(t *testing.T) {
+	r, ok := RectFromBbox(3, []float64{300, 200, 100, 50})
+	if !ok || r != (Rect{Page: 3, X0: 100, Y0: 50, X1: 300, Y1: 200}) {
+		t.Fatalf("unexpected rect %+v", r)
+	}
+	if _, ok := RectFromBbox(0, []float64{1, 2}); ok {
+		t.Fatalf("expected no rect for a short bbox")
+	}
+	if r.Width() != 200 || r.Height() != 150 || r.Area() != 30000 {
+		t.Fatalf("unexpected size %vx%v", r.Width(), r.Height())
+	}
+	if x, y := r.Center(); x != 200 || y != 125 {
+		t.Fatalf("unexpected center %v,%v", x, y)
+	}
+	if !r.Contains(100, 200) || r.Contains(99, 100) {
+		t.Fatalf("unexpected Contains")
+	}
+	if !r.Intersects(Rect{Page: 3, X0: 300, Y0: 0, X1: 400, Y1: 50}) || r.Intersects(Rect{Page: 2, X0: 100, Y0: 50, X1: 300, Y1: 200}) {
+		t.Fatalf("unexpected Intersects")
+	}
+	if u := r.Union(Rect{X0: 0, Y0: 100, X1: 150, Y1: 400}); u != (Rect{Page: 3, X0: 0, Y0: 50, X1: 300, Y1: 400}) {
+		t.Fatalf("unexpected union %+v", u)
+	}
+
+	doc := spatialDocument(t)
+	normalized := doc.NormalizeRect(Rect{Page: 1, X0: 306, Y0: 396, X1: 612, Y1: 792})
+	if normalized != (Rect{Page: 1, X0: 0.5, Y0: 0.5, X1: 1, Y1: 1}) {
+		t.Fatalf("unexpected normalized rect %+v", normalized)
+	}
+	if back := doc.DenormalizeRect(normalized); back != (Rect{Page: 1, X0: 306, Y0: 396, X1: 612, Y1: 792}) {
+		t.Fatalf("unexpected denormalized rect %+v", back)
+	}
+	if same := r.Normalize(0, 0); same != r {
+		t.Fatalf("normalizing against an unknown page size should keep the rect")
+	}
+}
+
+func TestSpatialQueries(t *testing.T) {
+	doc := spatialDocument(t)
+	blockIdxs := func(blocks []PageBlock) []int {
+		var idxs []int
+		for _, block := range blocks {
+			idxs = append(idxs, block.Node.NodeBlockIdx())
+		}
+		return idxs
+	}
+
+	inRect := doc.BlocksInRect(Rect{Page: 0, X0: 300, Y0: 100, X1: 600, Y1: 450})
+	if got := blockIdxs(inRect); len(got) != 3 || got[0] != 2 || got[1] != 3 || got[2] != 4 {
+		t.Fatalf("unexpected blocks in rect: %v", got)
+	}
+	if got := doc.BlocksInRect(Rect{Page: 0, X0: 600, Y0: 700, X1: 0, Y1: 10}); len(got) != 5 {
+		t.Fatalf("expected a reversed rect to be canonicalized, got %d blocks", len(got))
+	}
+
+	at := doc.BlocksAt(0, 100, 150)
+	if got := blockIdxs(at); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("unexpected blocks at point: %v", got)
+	}
+	if at := doc.BlocksAt(0, 305, 150); len(at) != 0 {
+		t.Fatalf("expected no block in the gutter, got %v", blockIdxs(at))
+	}
+	cellHit := doc.BlocksAt(1, 100, 30)
+	if len(cellHit) != 1 || cellHit[0].Cell == nil || cellHit[0].Cell.ToText() != "See next page." {
+		t.Fatalf("expected the table cell placed on page 1, got %v", blockIdxs(cellHit))
+	}
+	if at := doc.BlocksAt(7, 100, 30); len(at) != 0 {
+		t.Fatalf("expected no blocks on a missing page")
+	}
+
+	left := doc.Chunks()[0]
+	tests := []struct {
+		from      BlockInterface
+		direction Direction
+		want      int
+	}{
+		{left, DirectionRight, 2},
+		{left, DirectionUp, 0},
+		{left, DirectionDown, 4},
+		{doc.Chunks()[2], DirectionUp, 2},
+		{doc.Chunks()[2], DirectionLeft, 1},
+	}
+	for _, tt := range tests {
+		nearest, ok := doc.Nearest(tt.from, tt.direction)
+		if !ok || nearest.Node.NodeBlockIdx() != tt.want {
+			t.Errorf("Nearest(%d, %d) = %d, %v; want %d", tt.from.NodeBlockIdx(), tt.direction, nearest.Node.NodeBlockIdx(), ok, tt.want)
+		}
+	}
+	if _, ok := doc.Nearest(left, DirectionLeft); ok {
+		t.Fatalf("expected nothing left of the left column")
+	}
+}
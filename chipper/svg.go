@@ -0,0 +1,180 @@
+package chipper
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type SVGOptions struct {
+	// Colors overrides the stroke color of blocks by tag; table cells placed
+	// on a page of their own use the "cell" entry.
+	Colors map[string]string
+	// HideLabels leaves out the block index, level and section path drawn
+	// above every box.
+	HideLabels bool
+}
+
+// defaultSVGColors is the color scheme of page overlays by block tag.
+var defaultSVGColors = map[string]string{
+	"header":    "#d62728",
+	"para":      "#1f77b4",
+	"list_item": "#2ca02c",
+	"table":     "#ff7f0e",
+	"cell":      "#9467bd",
+	"":          "#7f7f7f",
+}
+
+// defaultPageSize is the US Letter page assumed when the document does not
+// know its page size.
+var defaultPageSize = [2]float64{612, 792}
+
+func (opts SVGOptions) color(tag string) string {
+	if color, ok := opts.Colors[tag]; ok {
+		return color
+	}
+	if color, ok := defaultSVGColors[tag]; ok {
+		return color
+	}
+	return defaultSVGColors[""]
+}
+
+// WritePageSVG draws the bounding boxes of the blocks on page as an SVG, each
+// outlined in the color of its tag and labelled with its block index, level
+// and section path. Hovering a box shows the start of its text.
+func (d *Document) WritePageSVG(w io.Writer, page int, opts SVGOptions) error {
+	pages := d.Pages()
+	if page < 0 || page >= len(pages) {
+		return fmt.Errorf("page %d out of range, the document has %d pages", page, len(pages))
+	}
+	_, err := io.WriteString(w, pageSVG(pages[page], opts))
+	return err
+}
+
+func pageSVG(page *Page, opts SVGOptions) string {
+	width, height := page.Width, page.Height
+	if width <= 0 || height <= 0 {
+		width, height = defaultPageSize[0], defaultPageSize[1]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect x="0" y="0" width="%g" height="%g" fill="white" stroke="#cccccc"/>`+"\n", width, height)
+	for _, block := range page.Blocks {
+		r, ok := block.Rect()
+		if !ok {
+			continue
+		}
+		tag := block.Node.NodeTag()
+		if block.Cell != nil {
+			tag = "cell"
+		}
+		color := opts.color(tag)
+		text := strings.Join(strings.Fields(block.Text()), " ")
+		fmt.Fprintf(&b, `<g class="%s">`, html.EscapeString(tag))
+		fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(truncateText(text, 200)))
+		fmt.Fprintf(&b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s" fill-opacity="0.08" stroke="%s" stroke-width="0.8"/>`,
+			r.X0, r.Y0, r.Width(), r.Height(), color, color)
+		if !opts.HideLabels {
+			fmt.Fprintf(&b, `<text x="%.2f" y="%.2f" font-size="6" fill="%s">%s</text>`,
+				r.X0, max(r.Y0-1.5, 6), color, html.EscapeString(blockLabel(block)))
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// blockLabel names a block by index, level and section path, such as
+// "#42 L2 Results of Operations > Revenue".
+func blockLabel(block PageBlock) string {
+	label := fmt.Sprintf("#%d", block.Node.NodeBlockIdx())
+	if block.Cell != nil {
+		label += " cell"
+	} else if level := block.Node.NodeLevel(); level >= 0 {
+		label += fmt.Sprintf(" L%d", level)
+	}
+	if path := sectionTitles(block.Node); len(path) > 0 {
+		label += " " + truncateText(strings.Join(path, " > "), 80)
+	}
+	return label
+}
+
+// sectionTitles returns the titles of the sections enclosing node, outermost
+// first, ending with node itself when it is a section.
+func sectionTitles(node BlockInterface) []string {
+	var titles []string
+	if section, ok := node.(*Section); ok {
+		titles = append(titles, section.Title)
+	}
+	for ancestor := range Ancestors(node) {
+		if section, ok := ancestor.(*Section); ok {
+			titles = append(titles, section.Title)
+		}
+	}
+	slices.Reverse(titles)
+	return titles
+}
+
+// truncateText shortens text to at most maxRunes runes, marking the cut with
+// an ellipsis.
+func truncateText(text string, maxRunes int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes-1]) + "…"
+}
+
+// WritePageSVGs writes one SVG per page holding blocks to dir, named
+// page-001.svg and so on after the one-based page number, and returns the
+// written paths.
+func (d *Document) WritePageSVGs(dir string, opts SVGOptions) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, page := range d.Pages() {
+		if len(page.Blocks) == 0 {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("page-%03d.svg", page.Index+1))
+		if err := os.WriteFile(path, []byte(pageSVG(page, opts)), 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// WriteHTMLReport writes a single HTML page with a color legend and the SVG
+// overlay of every page holding blocks.
+func (d *Document) WriteHTMLReport(w io.Writer, opts SVGOptions) error {
+	var b strings.Builder
+	title := "Layout report"
+	if d.SourceFile != "" {
+		title += ": " + d.SourceFile
+	}
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(title))
+	b.WriteString("<style>body{font-family:sans-serif;background:#f4f4f4}section{margin:24px 0}svg{background:white;box-shadow:0 1px 4px #999}.legend span{display:inline-block;margin-right:16px}</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<p class=\"legend\">", html.EscapeString(title))
+	for _, tag := range []string{"header", "para", "list_item", "table", "cell"} {
+		fmt.Fprintf(&b, `<span style="color:%s">■ %s</span>`, opts.color(tag), tag)
+	}
+	b.WriteString("</p>\n")
+	for _, page := range d.Pages() {
+		if len(page.Blocks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<section id=\"page-%d\">\n<h2>Page %d</h2>\n", page.Index+1, page.Index+1)
+		b.WriteString(pageSVG(page, opts))
+		b.WriteString("</section>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,103 @@
+package chipper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// svgElements checks that data is well-formed XML and counts its elements by
+// name.
+func svgElements(t *testing.T, data string) map[string]int {
+	t.Helper()
+	counts := map[string]int{}
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, data)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestWritePageSVG(t *testing.T) {
+	doc := spatialDocument(t)
+
+	var buf bytes.Buffer
+	if err := doc.WritePageSVG(&buf, 0, SVGOptions{Colors: map[string]string{"para": "black"}}); err != nil {
+		t.Fatalf("WritePageSVG failed: %v", err)
+	}
+	svg := buf.String()
+	counts := svgElements(t, svg)
+	if counts["svg"] != 1 || counts["rect"] != 6 || counts["text"] != 5 || counts["title"] != 5 {
+		t.Fatalf("unexpected elements %v", counts)
+	}
+	for _, want := range []string{
+		`viewBox="0 0 612 792"`,
+		`>#0 L0 Overview</text>`,
+		`>#1 L1 Overview</text>`,
+		`<g class="table"><title>| Total | See next page.</title>`,
+		`stroke="black"`,
+		`stroke="#d62728"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG does not contain %s:\n%s", want, svg)
+		}
+	}
+
+	buf.Reset()
+	if err := doc.WritePageSVG(&buf, 1, SVGOptions{HideLabels: true}); err != nil {
+		t.Fatalf("WritePageSVG failed: %v", err)
+	}
+	if counts := svgElements(t, buf.String()); counts["text"] != 0 || !strings.Contains(buf.String(), `<g class="cell">`) {
+		t.Fatalf("expected an unlabelled page with the cell placed on it, got %v", counts)
+	}
+	if err := doc.WritePageSVG(&buf, 5, SVGOptions{}); err == nil {
+		t.Fatalf("expected an error for a page out of range")
+	}
+}
+
+func TestWritePageSVGs(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	dir := t.TempDir()
+	paths, err := doc.WritePageSVGs(dir, SVGOptions{})
+	if err != nil {
+		t.Fatalf("WritePageSVGs failed: %v", err)
+	}
+	// Page index 10 holds no blocks.
+	if len(paths) != 105 || filepath.Base(paths[0]) != "page-001.svg" || filepath.Base(paths[10]) != "page-012.svg" {
+		t.Fatalf("unexpected files: %d, %s, %s", len(paths), filepath.Base(paths[0]), filepath.Base(paths[10]))
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		if counts := svgElements(t, string(data)); counts["rect"] < 2 {
+			t.Fatalf("%s has no blocks", path)
+		}
+	}
+
+	var buf bytes.Buffer
+	doc.SourceFile = "uber-10q.pdf"
+	if err := doc.WriteHTMLReport(&buf, SVGOptions{}); err != nil {
+		t.Fatalf("WriteHTMLReport failed: %v", err)
+	}
+	report := buf.String()
+	if strings.Count(report, "<svg ") != 105 || !strings.Contains(report, "<title>Layout report: uber-10q.pdf</title>") || !strings.Contains(report, `<section id="page-12">`) {
+		t.Fatalf("unexpected report of %d bytes", len(report))
+	}
+}
@@ -0,0 +1,313 @@
+package chipper
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// GridCell is one slot of the rectangular grid returned by Table.Grid. A cell
+// spanning several columns fills one slot per column, all pointing at the
+// same TableCell; OriginCol is the column where the span starts.
+type GridCell struct {
+	// Cell is nil for the padding of rows shorter than the table.
+	Cell      *TableCell
+	Row       int
+	Col       int
+	OriginCol int
+	Header    bool
+	FullRow   bool
+}
+
+// IsOrigin reports whether the slot is where its cell starts, so walking only
+// origin slots visits every cell once.
+func (gc GridCell) IsOrigin() bool {
+	return gc.Cell != nil && gc.Col == gc.OriginCol
+}
+
+func (gc GridCell) Text() string {
+	if gc.Cell == nil {
+		return ""
+	}
+	return gc.Cell.ToText()
+}
+
+// NumCols is the width of the table: the widest header or data row, counting
+// col_span. Full rows are stretched to this width rather than defining it.
+func (t *Table) NumCols() int {
+	numCols := 0
+	fullRowCols := 0
+	for _, header := range t.Headers {
+		numCols = max(numCols, spanWidth(header.Cells))
+	}
+	for _, row := range t.Rows {
+		if row.IsFullRow() {
+			fullRowCols = max(fullRowCols, spanWidth(row.Cells))
+		} else {
+			numCols = max(numCols, spanWidth(row.Cells))
+		}
+	}
+	if numCols == 0 {
+		return fullRowCols
+	}
+	return numCols
+}
+
+func spanWidth(cells []*TableCell) int {
+	width := 0
+	for _, cell := range cells {
+		width += max(cell.ColSpan, 1)
+	}
+	return width
+}
+
+// Grid returns the table as a rectangular matrix of NumCols columns: header
+// rows first, in the order of Table.Headers, followed by Table.Rows. Spanning
+// cells are expanded into every column they cover, full rows span the whole
+// width and short rows are padded with empty slots.
+func (t *Table) Grid() [][]GridCell {
+	numCols := t.NumCols()
+	grid := make([][]GridCell, 0, len(t.Headers)+len(t.Rows))
+	for _, header := range t.Headers {
+		grid = append(grid, gridRow(header.Cells, len(grid), numCols, true, false))
+	}
+	for _, row := range t.Rows {
+		grid = append(grid, gridRow(row.Cells, len(grid), numCols, false, row.IsFullRow()))
+	}
+	return grid
+}
+
+func gridRow(cells []*TableCell, rowIdx, numCols int, header, fullRow bool) []GridCell {
+	slots := make([]GridCell, numCols)
+	col := 0
+	for i, cell := range cells {
+		span := max(cell.ColSpan, 1)
+		if fullRow && i == len(cells)-1 {
+			span = numCols - col
+		}
+		origin := col
+		for ; col < origin+span && col < numCols; col++ {
+			slots[col] = GridCell{Cell: cell, Row: rowIdx, Col: col, OriginCol: origin, Header: header, FullRow: fullRow}
+		}
+	}
+	for ; col < numCols; col++ {
+		slots[col] = GridCell{Row: rowIdx, Col: col, OriginCol: col, Header: header, FullRow: fullRow}
+	}
+	return slots
+}
+
+type CSVOptions struct {
+	// Comma is the field delimiter, ',' by default.
+	Comma rune
+	// FlattenHeaders combines stacked header rows into a single header row
+	// of ColumnLabels.
+	FlattenHeaders bool
+	// FullRowLabels moves full_row section labels such as "Assets" into a
+	// leading column of the rows below them instead of writing them as rows.
+	FullRowLabels bool
+	// IncludeName writes the table name as the first record.
+	IncludeName bool
+}
+
+// CSVRecords lays the table out as spreadsheet records following opts. Spanned
+// cells keep their text in the first column they cover.
+func (t *Table) CSVRecords(opts CSVOptions) [][]string {
+	grid := t.Grid()
+	var records [][]string
+	if opts.IncludeName && t.Name != "" {
+		records = append(records, []string{t.Name})
+	}
+
+	rowText := func(slots []GridCell) []string {
+		record := make([]string, len(slots))
+		for c, slot := range slots {
+			if slot.IsOrigin() {
+				record[c] = slot.Text()
+			}
+		}
+		return record
+	}
+	leading := func(label string, record []string) []string {
+		if !opts.FullRowLabels {
+			return record
+		}
+		return append([]string{label}, record...)
+	}
+
+	headerRows := grid[:len(t.Headers)]
+	if opts.FlattenHeaders && len(headerRows) > 0 {
+		records = append(records, leading("", columnLabels(headerPaths(headerRows, len(headerRows[0])))))
+	} else {
+		for _, slots := range headerRows {
+			records = append(records, leading("", rowText(slots)))
+		}
+	}
+
+	label := ""
+	for _, slots := range grid[len(t.Headers):] {
+		if opts.FullRowLabels && len(slots) > 0 && slots[0].FullRow {
+			label = slots[0].Text()
+			continue
+		}
+		records = append(records, leading(label, rowText(slots)))
+	}
+	return records
+}
+
+// ColumnHeaderPaths returns, for every column of the grid, the distinct texts
+// of the stacked header rows above it from top to bottom. A header spanning
+// several columns contributes to each of them, so "Three Months Ended March
+// 31," over "2021 | 2022" yields two paths sharing their first element.
+func (t *Table) ColumnHeaderPaths() [][]string {
+	return headerPaths(t.Grid()[:len(t.Headers)], t.NumCols())
+}
+
+func headerPaths(headerRows [][]GridCell, numCols int) [][]string {
+	paths := make([][]string, numCols)
+	for c := range paths {
+		paths[c] = []string{}
+		for _, slots := range headerRows {
+			text := strings.Join(strings.Fields(slots[c].Text()), " ")
+			if text != "" && (len(paths[c]) == 0 || paths[c][len(paths[c])-1] != text) {
+				paths[c] = append(paths[c], text)
+			}
+		}
+	}
+	return paths
+}
+
+// ColumnLabels returns one label per column combining the stacked header
+// rows, such as "Three Months Ended March 31, 2021".
+func (t *Table) ColumnLabels() []string {
+	return columnLabels(t.ColumnHeaderPaths())
+}
+
+func columnLabels(paths [][]string) []string {
+	labels := make([]string, len(paths))
+	for c, path := range paths {
+		labels[c] = strings.Join(path, " ")
+	}
+	return labels
+}
+
+// ToMarkdown renders the table as a Markdown table with a single header row
+// of column labels. Full rows put their label in the first column.
+func (t *Table) ToMarkdown() string {
+	return t.markdownRows(0, len(t.Rows))
+}
+
+// markdownRows renders the headers and rows [start, end) of the table.
+func (t *Table) markdownRows(start, end int) string {
+	grid := t.Grid()
+	numCols := t.NumCols()
+	if numCols == 0 {
+		return ""
+	}
+	escape := func(text string) string {
+		return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", "\\|")
+	}
+	line := func(cells []string) string {
+		return "| " + strings.Join(cells, " | ") + " |\n"
+	}
+
+	var b strings.Builder
+	labels := columnLabels(headerPaths(grid[:len(t.Headers)], numCols))
+	for c := range labels {
+		labels[c] = escape(labels[c])
+	}
+	b.WriteString(line(labels))
+	b.WriteString(line(slices.Repeat([]string{"---"}, numCols)))
+	for _, slots := range grid[len(t.Headers)+start : len(t.Headers)+end] {
+		cells := make([]string, numCols)
+		for c, slot := range slots {
+			if slot.IsOrigin() {
+				cells[c] = escape(slot.Text())
+			}
+		}
+		b.WriteString(line(cells))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (t *Table) WriteCSV(w io.Writer, opts CSVOptions) error {
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+	if err := writer.WriteAll(t.CSVRecords(opts)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (t *Table) WriteTSV(w io.Writer, opts CSVOptions) error {
+	opts.Comma = '\t'
+	return t.WriteCSV(w, opts)
+}
+
+// ExportTables writes every table of the document to its own file in dir,
+// named after its position and table name, and returns the written paths.
+// Files get a .tsv extension when opts.Comma is a tab.
+func (d *Document) ExportTables(dir string, opts CSVOptions) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	ext := ".csv"
+	if opts.Comma == '\t' {
+		ext = ".tsv"
+	}
+
+	var paths []string
+	for i, node := range d.Tables() {
+		table := node.(*Table)
+		name := fmt.Sprintf("table-%03d", i+1)
+		if slug := slugify(table.Name, 40); slug != "" {
+			name += "-" + slug
+		}
+		path := filepath.Join(dir, name+ext)
+
+		file, err := os.Create(path)
+		if err != nil {
+			return paths, err
+		}
+		err = table.WriteCSV(file, opts)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// slugify turns text into a lowercase, dash separated file name fragment of at
+// most maxLen bytes.
+func slugify(text string, maxLen int) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if r > unicode.MaxASCII {
+				continue
+			}
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			if b.Len() >= maxLen {
+				break
+			}
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
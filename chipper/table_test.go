@@ -0,0 +1,379 @@
+package chipper
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTestTable(t *testing.T, tableJSON string) *Table {
+	t.Helper()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(tableJSON), &data); err != nil {
+		t.Fatalf("failed to decode table: %v", err)
+	}
+	return NewTable(data, nil)
+}
+
+func TestTableGrid(t *testing.T) {
+	table := newTestTable(t, `{"tag": "table", "name": "Ragged", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "Three Months Ended", "col_span": 2}, {"cell_value": "Total"}]},
+		{"type": "full_row", "cell_value": "Assets", "col_span": 3},
+		{"type": "table_data_row", "cells": [{"cell_value": "Cash"}, {"cell_value": "1"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Receivables"}, {"cell_value": "2"}, {"cell_value": "3"}, {"cell_value": "5"}]}
+	]}`)
+
+	if table.NumCols() != 4 {
+		t.Fatalf("expected 4 columns, got %d", table.NumCols())
+	}
+	grid := table.Grid()
+	if len(grid) != 4 {
+		t.Fatalf("expected 4 grid rows, got %d", len(grid))
+	}
+	for r, row := range grid {
+		if len(row) != 4 {
+			t.Fatalf("grid row %d has %d columns", r, len(row))
+		}
+	}
+
+	span := grid[0][2]
+	if span.Text() != "Three Months Ended" || span.OriginCol != 1 || span.IsOrigin() || !span.Header {
+		t.Fatalf("unexpected spanned header slot: %+v", span)
+	}
+	if !grid[0][1].IsOrigin() || grid[0][3].Text() != "Total" {
+		t.Fatal("header cells are misaligned")
+	}
+	for c := 0; c < 4; c++ {
+		if grid[1][c].Text() != "Assets" || !grid[1][c].FullRow || grid[1][c].OriginCol != 0 {
+			t.Fatalf("full row does not span column %d: %+v", c, grid[1][c])
+		}
+	}
+	if grid[2][2].Cell != nil || grid[2][3].Cell != nil {
+		t.Fatal("short row is not padded with empty slots")
+	}
+	if grid[3][3].Text() != "5" || grid[3][3].Row != 3 || grid[3][3].Col != 3 {
+		t.Fatalf("unexpected cell at (3, 3): %+v", grid[3][3])
+	}
+}
+
+func TestTableGridFixture(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	for _, node := range doc.Tables() {
+		table := node.(*Table)
+		cells := 0
+		for _, header := range table.Headers {
+			cells += len(header.Cells)
+		}
+		for _, row := range table.Rows {
+			cells += len(row.Cells)
+		}
+
+		origins := 0
+		for _, row := range table.Grid() {
+			if len(row) != table.NumCols() {
+				t.Fatalf("table %d: grid row has %d columns, expected %d", table.BlockIdx, len(row), table.NumCols())
+			}
+			for _, slot := range row {
+				if slot.IsOrigin() {
+					origins++
+				}
+			}
+		}
+		if origins != cells {
+			t.Fatalf("table %d: grid has %d cell origins for %d cells", table.BlockIdx, origins, cells)
+		}
+	}
+}
+
+func TestTableWriteCSV(t *testing.T) {
+	table := sampleDocument(t).Tables()[0].(*Table)
+
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf, CSVOptions{IncludeName: true, FullRowLabels: true}); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "Revenue by offering (in millions)\n" +
+		",,2021,2022\n" +
+		"Revenue,Mobility,\"$ 1,536\",\"$ 2,518\"\n" +
+		"Revenue,Delivery,\"1,713\",\"2,513\"\n" +
+		"Revenue,Freight,(26),—\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := table.WriteTSV(&buf, CSVOptions{}); err != nil {
+		t.Fatalf("WriteTSV failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 5 || lines[1] != "Revenue\t\t" {
+		t.Fatalf("unexpected TSV:\n%s", buf.String())
+	}
+
+	stacked := newTestTable(t, `{"tag": "table", "name": "", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "Three Months Ended March 31,", "col_span": 2}]},
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "2021"}, {"cell_value": "2022"}]},
+		{"type": "table_data_row", "cells": [{"cell_value": "Revenue"}, {"cell_value": "2,903"}, {"cell_value": "6,854"}]}
+	]}`)
+	records := stacked.CSVRecords(CSVOptions{FlattenHeaders: true})
+	if len(records) != 2 || records[0][1] != "Three Months Ended March 31, 2021" || records[0][2] != "Three Months Ended March 31, 2022" {
+		t.Fatalf("unexpected flattened headers: %q", records)
+	}
+}
+
+func TestExportTables(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	dir := t.TempDir()
+	paths, err := doc.ExportTables(dir, CSVOptions{Comma: '\t', FlattenHeaders: true})
+	if err != nil {
+		t.Fatalf("ExportTables failed: %v", err)
+	}
+	if len(paths) != len(doc.Tables()) {
+		t.Fatalf("expected %d files, got %d", len(doc.Tables()), len(paths))
+	}
+	for _, path := range paths {
+		if filepath.Ext(path) != ".tsv" {
+			t.Fatalf("unexpected file extension: %s", path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("missing exported table: %v", err)
+		}
+	}
+}
+
+func TestTableColumnLabels(t *testing.T) {
+	table := newTestTable(t, `{"tag": "table", "name": "", "table_rows": [
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "Three Months Ended March 31,", "col_span": 2}, {"cell_value": "Change"}]},
+		{"type": "table_header", "cells": [{"cell_value": ""}, {"cell_value": "2021"}, {"cell_value": "2022"}, {"cell_value": "%"}]},
+		{"type": "full_row", "cell_value": "Revenue", "col_span": 4},
+		{"type": "table_data_row", "cells": [{"cell_value": "Mobility | Rides"}, {"cell_value": "1,536"}, {"cell_value": "2,518"}, {"cell_value": "64 %"}]}
+	]}`)
+
+	want := []string{"", "Three Months Ended March 31, 2021", "Three Months Ended March 31, 2022", "Change %"}
+	labels := table.ColumnLabels()
+	if strings.Join(labels, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected column labels: %q", labels)
+	}
+	if paths := table.ColumnHeaderPaths(); len(paths[1]) != 2 || paths[1][0] != paths[2][0] {
+		t.Fatalf("spanned header should prefix both year columns: %q", paths)
+	}
+
+	wantMarkdown := "|  | Three Months Ended March 31, 2021 | Three Months Ended March 31, 2022 | Change % |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| Revenue |  |  |  |\n" +
+		"| Mobility \\| Rides | 1,536 | 2,518 | 64 % |"
+	if markdown := table.ToMarkdown(); markdown != wantMarkdown {
+		t.Fatalf("unexpected Markdown:\n%s\nwant:\n%s", markdown, wantMarkdown)
+	}
+}
+
+func TestTableCaptions(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+	byIdx := make(map[int]*Table)
+	for _, node := range doc.Tables() {
+		table := node.(*Table)
+		byIdx[table.BlockIdx] = table
+	}
+
+	balance, segment, research := byIdx[74], byIdx[937], byIdx[869]
+	if balance == nil || segment == nil || research == nil {
+		t.Fatalf("fixture tables 74, 937 and 869 not found: %v, %v, %v", balance != nil, segment != nil, research != nil)
+	}
+	if !strings.HasPrefix(balance.UnitNote, "(In millions, except share amounts") {
+		t.Fatalf("expected the balance sheet unit note, got %q", balance.UnitNote)
+	}
+	if _, ok := balance.Caption.(*Section); !ok {
+		t.Fatalf("expected the balance sheet caption to be the header above the unit note, got %q", balance.CaptionText())
+	}
+	if scale, _ := balance.ScaleHint(); scale != 1e6 {
+		t.Fatalf("expected the balance sheet to be in millions, got %v", scale)
+	}
+
+	if segment.UnitNote != "" && !strings.Contains(strings.ToLower(segment.UnitNote), "in millions") {
+		t.Fatalf("unexpected unit note: %q", segment.UnitNote)
+	}
+	if lines := strings.Split(segment.FootnoteText(), "\n"); len(lines) != 5 || !strings.HasPrefix(lines[0], "(1) Includes historical results") || lines[4] != "* Percentage not meaningful." {
+		t.Fatalf("unexpected footnotes (%d):\n%s", len(segment.Footnotes), segment.FootnoteText())
+	}
+
+	if research.FootnoteText() != "* Percentage not meaningful." {
+		t.Fatalf("unexpected footnotes: %q", research.FootnoteText())
+	}
+	if text := research.ToContextText(false); !strings.HasPrefix(text, research.CaptionText()) || !strings.HasSuffix(text, "* Percentage not meaningful.") {
+		t.Fatalf("context text is missing the caption or footnotes:\n%s", text)
+	}
+}
+
+func TestTableCaptionsInBodyText(t *testing.T) {
+	long := strings.Repeat("Revenue is recognized when control passes to the customer. ", 6)
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "para", "level": 1, "page_idx": 3, "sentences": ["`+long+`"]},
+		{"tag": "table", "level": 2, "page_idx": 3, "name": "", "table_rows": [
+			{"type": "table_data_row", "cells": [{"cell_value": "Mobility"}, {"cell_value": "1"}]}
+		]},
+		{"tag": "list_item", "level": 1, "page_idx": 3, "sentences": ["(a) The Company sells rides."]},
+		{"tag": "para", "level": 1, "page_idx": 3, "sentences": ["The components were as follows (in millions):"]},
+		{"tag": "table", "level": 2, "page_idx": 3, "name": "", "table_rows": [
+			{"type": "table_data_row", "cells": [{"cell_value": "Delivery"}, {"cell_value": "2"}]}
+		]},
+		{"tag": "list_item", "level": 1, "page_idx": 3, "sentences": ["(1)"]},
+		{"tag": "para", "level": 1, "page_idx": 3, "sentences": ["Includes Freight."]},
+		{"tag": "para", "level": 1, "page_idx": 4, "sentences": ["* Starts the next page."]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	tables := NewDocument(blocks).Tables()
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got %d", len(tables))
+	}
+
+	// Body text around a table is neither its caption nor its footnotes.
+	body := tables[0].(*Table)
+	if body.Caption != nil || len(body.Footnotes) != 0 {
+		t.Errorf("body text taken as caption %q or footnotes %q", body.CaptionText(), body.FootnoteText())
+	}
+	notes := tables[1].(*Table)
+	if notes.CaptionText() != "The components were as follows (in millions):" || notes.UnitNote != "(in millions)" {
+		t.Errorf("unexpected caption %q and unit note %q", notes.CaptionText(), notes.UnitNote)
+	}
+	if notes.FootnoteText() != "(1) Includes Freight." {
+		t.Errorf("unexpected footnotes %q", notes.FootnoteText())
+	}
+}
+
+func TestTableCellContent(t *testing.T) {
+	table := newTestTable(t, `{"tag": "table", "name": "Offerings", "page_idx": 26, "table_rows": [
+		{"type": "table_data_row", "cells": [
+			{"cell_value": "Mobility"},
+			{"cell_value": {"tag": "para", "page_idx": 26, "bbox": [132, 130, 550, 146], "sentences": ["Rides in cars.", "Also taxis."]}}
+		]},
+		{"type": "table_data_row", "cells": [
+			{"cell_value": "Delivery"},
+			{"cell_value": [
+				{"tag": "para", "page_idx": 26, "bbox": [132, 150, 500, 160], "sentences": ["Includes:"]},
+				{"tag": "list_item", "level": 1, "page_idx": 26, "bbox": [140, 162, 520, 170], "sentences": ["Grocery"]},
+				{"tag": "list_item", "level": 2, "page_idx": 26, "bbox": [150, 172, 520, 180], "sentences": ["Alcohol"]}
+			]}
+		]},
+		{"type": "table_data_row", "cells": [{"cell_value": null}, {"cell_value": 42.5}, {}, {"cell_value": true}]}
+	]}`)
+
+	mobility := table.Rows[0].Cells[1]
+	if mobility.CellNode == nil || mobility.ToText() != "Rides in cars.\nAlso taxis." {
+		t.Fatalf("unexpected paragraph cell: %q", mobility.ToText())
+	}
+	if mobility.PageIdx != 26 || len(mobility.Bbox) != 4 || mobility.Bbox[0] != 132 {
+		t.Fatalf("cell did not inherit the paragraph position: page %d, bbox %v", mobility.PageIdx, mobility.Bbox)
+	}
+
+	delivery := table.Rows[1].Cells[1]
+	if len(delivery.Nodes) != 2 {
+		t.Fatalf("expected a paragraph and a list in the cell, got %d nodes", len(delivery.Nodes))
+	}
+	if text := delivery.ToText(); text != "Includes:\nGrocery\nAlcohol" {
+		t.Fatalf("unexpected nested cell text: %q", text)
+	}
+	if got := delivery.ToHTML(); got != "<td><p>Includes:</p><ul><li>Grocery<ul><li>Alcohol</li></ul></li></ul></td>" {
+		t.Fatalf("unexpected nested cell HTML: %s", got)
+	}
+	if delivery.Bbox[1] != 150 || delivery.Bbox[3] != 180 {
+		t.Fatalf("cell bbox should cover its nested blocks, got %v", delivery.Bbox)
+	}
+
+	values := table.Rows[2].Cells
+	if len(values) != 4 || values[0].ToText() != "" || values[1].ToText() != "42.5" || values[2].ToText() != "" || values[3].ToText() != "true" {
+		t.Fatalf("unexpected scalar cells: %q %q %q", values[0].ToText(), values[1].ToText(), values[2].ToText())
+	}
+	if value := values[1].Value(); value.Kind != CellNumber || value.Number != 42.5 {
+		t.Fatalf("unexpected numeric cell value: %+v", value)
+	}
+
+	if markdown := table.ToMarkdown(); !strings.Contains(markdown, "| Delivery | Includes: Grocery Alcohol |") {
+		t.Fatalf("nested cell content missing from Markdown:\n%s", markdown)
+	}
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf, CSVOptions{}); err != nil || !strings.Contains(buf.String(), "\"Includes:\nGrocery\nAlcohol\"") {
+		t.Fatalf("nested cell content missing from CSV (%v):\n%s", err, buf.String())
+	}
+}
+
+func TestTableCellNodeAncestors(t *testing.T) {
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"tag": "header", "level": 1, "block_idx": 0, "sentences": ["Offerings"]},
+		{"tag": "table", "level": 1, "block_idx": 1, "name": "Platforms", "table_rows": [
+			{"type": "table_data_row", "cells": [
+				{"cell_value": "Delivery"},
+				{"cell_value": [
+					{"tag": "para", "sentences": ["Includes:"]},
+					{"tag": "list_item", "level": 1, "sentences": ["Grocery"]},
+					{"tag": "list_item", "level": 2, "sentences": ["Alcohol"]}
+				]}
+			]}
+		]}
+	]`), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks: %v", err)
+	}
+	doc := NewDocument(blocks)
+	section := doc.Sections()[0]
+	table := doc.Tables()[0].(*Table)
+	cell := table.Rows[0].Cells[1]
+
+	// The nested blocks hang off the table, so their ancestors reach the
+	// table and its section, without becoming children of the table.
+	includes, alcohol := cell.Nodes[0], cell.Nodes[1].ChildNodes()[0]
+	if includes.ParentNode() != table || len(table.ChildNodes()) != 0 {
+		t.Fatalf("expected the table as parent of the cell paragraph, got %v", includes.ParentNode())
+	}
+	ancestors := slices.Collect(Ancestors(alcohol))
+	if len(ancestors) != 4 || ancestors[1] != table || ancestors[2] != section {
+		t.Fatalf("unexpected ancestors of a nested list item: %v", ancestors)
+	}
+	if chain := blockOf(alcohol).ParentChain(); len(chain) != 4 || chain[1] != section || chain[2] != table {
+		t.Fatalf("unexpected parent chain of a nested list item: %v", chain)
+	}
+	if depth := blockOf(alcohol).Depth(); depth != table.Depth()+2 {
+		t.Fatalf("nested list item at depth %d, table at %d", depth, table.Depth())
+	}
+	if includes.NextSibling() != nil || includes.PrevSibling() != nil || blockOf(includes).Prev() != table {
+		t.Fatalf("cell blocks must not be siblings of the table children")
+	}
+}
+
+func TestNestedTableWithoutName(t *testing.T) {
+	table := newTestTable(t, `{"tag": "table", "name": "Segments", "table_rows": [
+		"not a row",
+		{"type": "table_data_row", "cells": [
+			{"cell_value": "Mobility"},
+			{"cell_value": {"tag": "table", "table_rows": [
+				7,
+				{"type": "table_data_row", "cells": [{"cell_value": "Rides"}, {"cell_value": "1"}]}
+			]}}
+		]}
+	]}`)
+
+	if len(table.Rows) != 1 {
+		t.Fatalf("expected rows that are not objects to be skipped, got %d rows", len(table.Rows))
+	}
+	cell := table.Rows[0].Cells[1]
+	if len(cell.Nodes) != 1 {
+		t.Fatalf("expected the nested table in the cell, got %d nodes", len(cell.Nodes))
+	}
+	nested, ok := cell.Nodes[0].(*Table)
+	if !ok || nested.Name != "" || len(nested.Rows) != 1 || nested.Rows[0].Cells[0].ToText() != "Rides" {
+		t.Fatalf("unexpected nested table %+v", cell.Nodes[0])
+	}
+}
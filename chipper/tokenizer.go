@@ -0,0 +1,30 @@
+package chipper
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a piece of text costs against a model budget.
+// Plug in the tokenizer of the target model for exact budgets.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+type TokenizerFunc func(text string) int
+
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// DefaultTokenizer estimates tokens with the common four characters per token
+// rule, never counting fewer tokens than words.
+var DefaultTokenizer Tokenizer = TokenizerFunc(approxTokenCount)
+
+func approxTokenCount(text string) int {
+	tokens := (utf8.RuneCountInString(text) + 3) / 4
+	if words := len(strings.Fields(text)); words > tokens {
+		tokens = words
+	}
+	return tokens
+}
@@ -0,0 +1,110 @@
+package chipper
+
+// WalkAction tells Walk how to proceed after a visitor callback.
+type WalkAction int
+
+const (
+	WalkContinue WalkAction = iota
+	// WalkSkipChildren returned from Enter skips the children of the node;
+	// Leave is still called for it. From Leave it is the same as WalkContinue.
+	WalkSkipChildren
+	// WalkAbort stops the walk without any further callbacks.
+	WalkAbort
+)
+
+// WalkContext describes where a visited node sits relative to the node the
+// walk started from.
+type WalkContext struct {
+	// Depth is 0 for the starting node, 1 for its children and so on.
+	Depth int
+	// Path holds the ancestors of the node from the starting node down to
+	// its parent. It is only valid during the callback; copy it to keep it.
+	Path []BlockInterface
+}
+
+// Parent returns the parent of the visited node, or nil for the starting node.
+func (ctx WalkContext) Parent() BlockInterface {
+	if len(ctx.Path) == 0 {
+		return nil
+	}
+	return ctx.Path[len(ctx.Path)-1]
+}
+
+// Visitor receives the nodes of a walk. Enter is called before the children
+// of a node are visited and Leave after them.
+type Visitor interface {
+	Enter(node BlockInterface, ctx WalkContext) WalkAction
+	Leave(node BlockInterface, ctx WalkContext) WalkAction
+}
+
+// WalkFunc is a Visitor that only needs to see nodes on entering them.
+type WalkFunc func(node BlockInterface, ctx WalkContext) WalkAction
+
+func (f WalkFunc) Enter(node BlockInterface, ctx WalkContext) WalkAction {
+	return f(node, ctx)
+}
+
+func (f WalkFunc) Leave(node BlockInterface, ctx WalkContext) WalkAction {
+	return WalkContinue
+}
+
+// VisitorFuncs builds a Visitor from a pair of functions, either of which may
+// be nil.
+type VisitorFuncs struct {
+	OnEnter WalkFunc
+	OnLeave WalkFunc
+}
+
+func (v VisitorFuncs) Enter(node BlockInterface, ctx WalkContext) WalkAction {
+	if v.OnEnter == nil {
+		return WalkContinue
+	}
+	return v.OnEnter(node, ctx)
+}
+
+func (v VisitorFuncs) Leave(node BlockInterface, ctx WalkContext) WalkAction {
+	if v.OnLeave == nil {
+		return WalkContinue
+	}
+	return v.OnLeave(node, ctx)
+}
+
+// Walk visits node and its descendants depth first in document order. The
+// children of a node are read after Enter returns, so Enter may rearrange
+// them. Walk reports whether it ran to completion rather than being aborted.
+func Walk(node BlockInterface, v Visitor) bool {
+	if node == nil {
+		return true
+	}
+	w := &walker{visitor: v}
+	return w.walk(node)
+}
+
+type walker struct {
+	visitor Visitor
+	path    []BlockInterface
+}
+
+func (w *walker) walk(node BlockInterface) bool {
+	ctx := WalkContext{Depth: len(w.path), Path: w.path[:len(w.path):len(w.path)]}
+	action := w.visitor.Enter(node, ctx)
+	if action == WalkAbort {
+		return false
+	}
+	if action != WalkSkipChildren {
+		w.path = append(w.path, node)
+		for _, child := range childrenOf(node) {
+			if !w.walk(child) {
+				return false
+			}
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	return w.visitor.Leave(node, ctx) != WalkAbort
+}
+
+// Walk walks the document tree from its root, an untagged Block at depth 0
+// holding the top-level blocks.
+func (d *Document) Walk(v Visitor) bool {
+	return Walk(d.rootNode, v)
+}
@@ -0,0 +1,112 @@
+package chipper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	doc := sampleDocument(t)
+
+	var events []string
+	completed := doc.Walk(VisitorFuncs{
+		OnEnter: func(node BlockInterface, ctx WalkContext) WalkAction {
+			if ctx.Depth > 0 && ctx.Parent() == nil {
+				t.Errorf("node at depth %d has no parent", ctx.Depth)
+			}
+			events = append(events, fmt.Sprintf("enter %s %d/%d", blockOf(node).Tag, ctx.Depth, len(ctx.Path)))
+			return WalkContinue
+		},
+		OnLeave: func(node BlockInterface, ctx WalkContext) WalkAction {
+			events = append(events, "leave "+blockOf(node).Tag)
+			return WalkContinue
+		},
+	})
+	want := []string{
+		"enter  0/0",
+		"enter header 1/1",
+		"enter para 2/2", "leave para",
+		"enter list_item 2/2", "leave list_item",
+		"enter table 2/2", "leave table",
+		"leave header",
+		"leave ",
+	}
+	if !completed || strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected walk (completed %v):\n%s", completed, strings.Join(events, "\n"))
+	}
+
+	var visited []string
+	doc.Walk(WalkFunc(func(node BlockInterface, ctx WalkContext) WalkAction {
+		visited = append(visited, blockOf(node).Tag)
+		if _, ok := node.(*Section); ok {
+			return WalkSkipChildren
+		}
+		return WalkContinue
+	}))
+	if strings.Join(visited, ",") != ",header" {
+		t.Fatalf("skipping section children still visited %v", visited)
+	}
+
+	visited = nil
+	leaves := 0
+	completed = doc.Walk(VisitorFuncs{
+		OnEnter: func(node BlockInterface, ctx WalkContext) WalkAction {
+			visited = append(visited, blockOf(node).Tag)
+			if _, ok := node.(*ListItem); ok {
+				return WalkAbort
+			}
+			return WalkContinue
+		},
+		OnLeave: func(node BlockInterface, ctx WalkContext) WalkAction {
+			leaves++
+			return WalkContinue
+		},
+	})
+	if completed || strings.Join(visited, ",") != ",header,para,list_item" || leaves != 1 {
+		t.Fatalf("abort did not stop the walk: completed %v, visited %v, %d leaves", completed, visited, leaves)
+	}
+}
+
+func TestWalkCollectors(t *testing.T) {
+	doc, err := ReadPDFTest()
+	if err != nil {
+		t.Fatalf("ReadPDFTest failed: %v", err)
+	}
+
+	counts := map[string]int{}
+	doc.Walk(WalkFunc(func(node BlockInterface, ctx WalkContext) WalkAction {
+		for _, ancestor := range ctx.Path {
+			if ancestor == node {
+				t.Fatalf("block %d is its own ancestor", blockOf(node).BlockIdx)
+			}
+		}
+		if ctx.Depth > 0 && node.ParentNode() != ctx.Parent() {
+			t.Fatalf("block %d has a Parent other than the node it was reached from", blockOf(node).BlockIdx)
+		}
+		switch node.(type) {
+		case *Section:
+			counts["sections"]++
+		case *Table:
+			counts["tables"]++
+		case *Paragraph:
+			counts["paragraphs"]++
+		case *ListItem:
+			counts["list items"]++
+		}
+		return WalkContinue
+	}))
+
+	if got := len(doc.Sections()); got != counts["sections"] {
+		t.Errorf("Sections returned %d nodes, walk found %d", got, counts["sections"])
+	}
+	if got := len(doc.Tables()); got != counts["tables"] {
+		t.Errorf("Tables returned %d nodes, walk found %d", got, counts["tables"])
+	}
+	if got := len(doc.rootNode.Paragraphs()); got != counts["paragraphs"] {
+		t.Errorf("Paragraphs returned %d nodes, walk found %d", got, counts["paragraphs"])
+	}
+	if got, want := len(doc.Chunks()), counts["paragraphs"]+counts["list items"]+counts["tables"]; got != want {
+		t.Errorf("Chunks returned %d nodes, walk found %d", got, want)
+	}
+}